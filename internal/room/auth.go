@@ -0,0 +1,172 @@
+package room
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrCredentialsRequired = errors.New("room requires a password or invite token")
+	ErrInvalidCredentials  = errors.New("invalid password or invite token")
+	ErrSecretTooLong       = errors.New("room password can be at most 72 bytes")
+	ErrTooManyAttempts     = errors.New("too many failed attempts - try again in a minute")
+)
+
+// Failed joins are limited per address, against guessing room codes, and
+// per room, against guessing its password from many accounts at once.
+const (
+	maxJoinFailures = 5
+	maxRoomFailures = 20
+	failureWindow   = time.Minute
+)
+
+// DefaultInviteTTL is how long a freshly minted invite token stays valid.
+const DefaultInviteTTL = 15 * time.Minute
+
+var tokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// SetSecret protects the room with a password, stored as a bcrypt hash.
+// An empty secret removes it.
+func (r *Room) SetSecret(secret string) error {
+	hash, err := hashSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secretHash = hash
+	return nil
+}
+
+// hashSecret bcrypts a room password, or returns nil for none. It is slow
+// on purpose, so callers mustn't hold a lock others are waiting on.
+func hashSecret(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	if len(secret) > 72 {
+		return nil, ErrSecretTooLong
+	}
+	return bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+}
+
+// HasSecret reports whether joining requires a password or invite token.
+func (r *Room) HasSecret() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.secretHash) > 0
+}
+
+// MintInvite creates a single-use token that lets one person in without the password.
+func (r *Room) MintInvite(ttl time.Duration) (token string, expires time.Time) {
+	buf := make([]byte, 5)
+	rand.Read(buf)
+	token = strings.ToLower(tokenEncoding.EncodeToString(buf))
+	expires = time.Now().Add(ttl)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.invites == nil {
+		r.invites = make(map[string]time.Time)
+	}
+	// drop expired tokens while we're here so the map can't grow unbounded
+	now := time.Now()
+	for t, exp := range r.invites {
+		if now.After(exp) {
+			delete(r.invites, t)
+		}
+	}
+	r.invites[token] = expires
+	return token, expires
+}
+
+// authorize checks a credential against the room's invite tokens,
+// consuming it if it is one, and then, if tryPassword is set, the room
+// password. bcrypt is slow on purpose, so that runs without holding r.mu.
+func (r *Room) authorize(credential string, tryPassword bool) error {
+	r.mu.Lock()
+	hash := r.secretHash
+	if len(hash) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	if credential == "" {
+		r.mu.Unlock()
+		return ErrCredentialsRequired
+	}
+	token := strings.ToLower(credential)
+	if exp, ok := r.invites[token]; ok {
+		delete(r.invites, token)
+		if time.Now().Before(exp) {
+			r.mu.Unlock()
+			return nil
+		}
+	}
+	r.mu.Unlock()
+
+	if !tryPassword {
+		return ErrTooManyAttempts
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(credential)) != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// failureLimiter counts recent failures per key, e.g. wrong passwords.
+type failureLimiter struct {
+	mu       sync.Mutex
+	max      int
+	failures map[string][]time.Time
+}
+
+func newFailureLimiter(max int) *failureLimiter {
+	return &failureLimiter{max: max, failures: make(map[string][]time.Time)}
+}
+
+// blocked reports whether key has failed too often lately
+func (l *failureLimiter) blocked(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.recentLocked(key, time.Now())) >= l.max
+}
+
+// fail records a failure for key
+func (l *failureLimiter) fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.failures[key] = append(l.recentLocked(key, now), now)
+	// forget keys that have gone quiet so the map can't grow unbounded
+	if len(l.failures) > 1000 {
+		for k := range l.failures {
+			l.recentLocked(k, now)
+		}
+	}
+}
+
+// recentLocked drops key's failures older than failureWindow and returns
+// the rest; callers must hold l.mu
+func (l *failureLimiter) recentLocked(key string, now time.Time) []time.Time {
+	times := l.failures[key]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) > failureWindow {
+		i++
+	}
+	times = times[i:]
+	if len(times) == 0 {
+		delete(l.failures, key)
+		return nil
+	}
+	l.failures[key] = times
+	return times
+}
@@ -0,0 +1,82 @@
+package room
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// chatHistoryLimit caps how many chat messages a room keeps
+const chatHistoryLimit = 500
+
+// ChatMessage is a human-to-human message in the room chat.
+type ChatMessage struct {
+	ClientID string   `json:"client_id"`
+	Username string   `json:"username"`
+	Text     string   `json:"text"`
+	Mentions []string `json:"mentions,omitempty"` // usernames @mentioned in Text
+	Ts       int64    `json:"ts"`
+}
+
+// MentionsUser reports whether username was @mentioned in the message.
+func (c ChatMessage) MentionsUser(username string) bool {
+	for _, m := range c.Mentions {
+		if strings.EqualFold(m, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// PostChat adds a message from clientID to the room chat and sends it to everyone.
+func (r *Room) PostChat(clientID, text string) error {
+	r.mu.Lock()
+	c := r.clientLocked(clientID)
+	if c == nil {
+		r.mu.Unlock()
+		return ErrNotInRoom
+	}
+
+	msg := ChatMessage{
+		ClientID: clientID,
+		Username: c.Name,
+		Text:     text,
+		Mentions: parseMentions(text),
+		Ts:       time.Now().UnixMilli(),
+	}
+	r.Chat = append(r.Chat, msg)
+	if len(r.Chat) > chatHistoryLimit {
+		r.Chat = r.Chat[len(r.Chat)-chatHistoryLimit:]
+	}
+	r.lastActivity = time.Now()
+	r.broadcastLocked(ChatEvent{Message: msg}, "")
+	r.mu.Unlock()
+
+	return r.persist()
+}
+
+// GetChat returns a copy of the room chat history.
+func (r *Room) GetChat() []ChatMessage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]ChatMessage, len(r.Chat))
+	copy(result, r.Chat)
+	return result
+}
+
+// parseMentions pulls @names out of text, ignoring trailing punctuation
+func parseMentions(text string) []string {
+	var mentions []string
+	for _, word := range strings.Fields(text) {
+		if !strings.HasPrefix(word, "@") {
+			continue
+		}
+		name := strings.TrimRightFunc(word[1:], func(r rune) bool {
+			return unicode.IsPunct(r) && r != '-' && r != '_'
+		})
+		if name != "" {
+			mentions = append(mentions, name)
+		}
+	}
+	return mentions
+}
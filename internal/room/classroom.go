@@ -0,0 +1,102 @@
+package room
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jaypopat/duet/internal/terminal"
+)
+
+var ErrNotClassroom = errors.New("not a classroom")
+
+// RosterEntry is one student as the instructor sees them.
+type RosterEntry struct {
+	ClientID string
+	Username string
+	Shell    Tab  // the student's private terminal, if HasShell
+	HasShell bool // started a shell of their own
+	InShell  bool // looking at it rather than the presenter's terminal
+}
+
+// MyShell returns a classroom participant's private terminal, starting it
+// at the given size the first time. Only its owner can type into it.
+func (r *Room) MyShell(clientID string, width, height int) (Tab, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Mode != ModeClassroom {
+		return Tab{}, ErrNotClassroom
+	}
+	c := r.clientLocked(clientID)
+	if c == nil {
+		return Tab{}, ErrNotInRoom
+	}
+	if t, ok := r.shells[clientID]; ok {
+		return *t, nil
+	}
+
+	term := terminal.New(width, height, r.template.terminalOptions(false))
+	if err := term.Start(); err != nil {
+		return Tab{}, err
+	}
+	t := &Tab{ID: uuid.New().String(), Name: c.Name + "'s shell", Terminal: term}
+	if r.shells == nil {
+		r.shells = make(map[string]*Tab)
+	}
+	r.shells[clientID] = t
+	return *t, nil
+}
+
+// Roster lists the connected students for the host, so they can peek at
+// anyone's private shell.
+func (r *Room) Roster(viewerID string) ([]RosterEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := r.requireHostLocked(viewerID); err != nil {
+		return nil, err
+	}
+	if r.Mode != ModeClassroom {
+		return nil, ErrNotClassroom
+	}
+
+	var roster []RosterEntry
+	for _, c := range r.Connections {
+		if c.IsHost() {
+			continue
+		}
+		e := RosterEntry{ClientID: c.ID, Username: c.Name}
+		if t, ok := r.shells[c.ID]; ok {
+			e.Shell, e.HasShell = *t, true
+			e.InShell = c.Viewing == t.ID
+		}
+		roster = append(roster, e)
+	}
+	return roster, nil
+}
+
+// closeShell kills a participant's private terminal once they've left for good
+func (r *Room) closeShell(clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeShellLocked(clientID)
+}
+
+// closeShellLocked is closeShell for callers that hold r.mu
+func (r *Room) closeShellLocked(clientID string) {
+	if t, ok := r.shells[clientID]; ok {
+		t.Terminal.Close()
+		delete(r.shells, clientID)
+	}
+}
+
+// ownShell returns clientID's private terminal if tabID is it
+func (r *Room) ownShell(clientID, tabID string) *terminal.Terminal {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if t, ok := r.shells[clientID]; ok && t.ID == tabID {
+		return t.Terminal
+	}
+	return nil
+}
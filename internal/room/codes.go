@@ -0,0 +1,50 @@
+package room
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Word lists for room codes. Kept short and easy to say out loud - no
+// homophones or words that are awkward to spell when dictated.
+var codeAdjectives = []string{
+	"brave", "calm", "clever", "cosy", "eager", "fancy", "gentle", "happy",
+	"jolly", "keen", "lucky", "mellow", "nimble", "proud", "quick", "quiet",
+	"rapid", "shiny", "silly", "sunny", "swift", "tidy", "witty", "zesty",
+}
+
+var codeAnimals = []string{
+	"badger", "beaver", "bison", "camel", "crane", "dingo", "eagle", "ferret",
+	"gecko", "heron", "koala", "lemur", "llama", "moose", "otter", "panda",
+	"puffin", "rabbit", "raven", "salmon", "tiger", "turtle", "walrus", "zebra",
+}
+
+// maxCodeAttempts bounds how long we keep drawing codes before widening the
+// numeric suffix, so a crowded server can never spin forever.
+const maxCodeAttempts = 20
+
+// generateCode returns a code like "brave-otter-42" that taken does not report as in use
+func generateCode(taken func(string) bool) string {
+	suffix := 100
+	for {
+		for range maxCodeAttempts {
+			code := fmt.Sprintf("%s-%s-%d",
+				codeAdjectives[rand.IntN(len(codeAdjectives))],
+				codeAnimals[rand.IntN(len(codeAnimals))],
+				rand.IntN(suffix),
+			)
+			if !taken(code) {
+				return code
+			}
+		}
+		suffix *= 10
+	}
+}
+
+// NormalizeCode folds what a user typed into the canonical room key:
+// lowercase, trimmed, with spaces accepted in place of hyphens.
+func NormalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.Join(strings.Fields(strings.ReplaceAll(code, "-", " ")), "-")
+}
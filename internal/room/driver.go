@@ -0,0 +1,154 @@
+package room
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrNotDriver = errors.New("someone else has the keyboard - ctrl+o to request it")
+
+// DriverIdleTimeout is how long the driver can go without typing before
+// the keyboard is released for anyone to take.
+var DriverIdleTimeout = 60 * time.Second
+
+// Driver returns the client currently holding the keyboard, or nil if it's free.
+func (r *Room) Driver() *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clientLocked(r.driverID)
+}
+
+// claimKeyboard lets clientID type if it already drives, or takes the
+// keyboard when it is free or the driver has gone idle.
+func (r *Room) claimKeyboard(clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.driverID != clientID {
+		// in a mob the rotation decides, however idle the driver is
+		if r.driverID != "" && (r.mob != nil || time.Since(r.lastInput) < DriverIdleTimeout) {
+			return ErrNotDriver
+		}
+		r.passKeyboardLocked(clientID)
+	}
+	r.lastInput = time.Now()
+	return nil
+}
+
+// RequestControl asks for the keyboard. It is granted straight away when
+// nobody is driving; otherwise the driver is prompted to grant or deny.
+// The returned bool reports whether control was granted immediately.
+func (r *Room) RequestControl(clientID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.clientLocked(clientID)
+	if c == nil || !c.Role.CanWrite() || r.muted[clientID] {
+		return false
+	}
+	if r.driverID == clientID {
+		return true
+	}
+	if r.driverID == "" || (r.mob == nil && time.Since(r.lastInput) >= DriverIdleTimeout) {
+		r.passKeyboardLocked(clientID)
+		return true
+	}
+
+	r.pendingDriverID = clientID
+	r.sendLocked(r.driverID, ControlRequestEvent{ClientID: clientID, Username: c.Name})
+	return false
+}
+
+// GrantControl hands the keyboard to whoever requested it. Only the current
+// driver can grant. In a mob the requester's turn starts now.
+func (r *Room) GrantControl(driverID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.driverID != driverID || r.pendingDriverID == "" {
+		return
+	}
+	next := r.pendingDriverID
+	r.pendingDriverID = ""
+	r.passKeyboardLocked(next)
+}
+
+// DenyControl turns down the pending request and lets the requester know.
+func (r *Room) DenyControl(driverID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.driverID != driverID || r.pendingDriverID == "" {
+		return
+	}
+	var driverName string
+	if c := r.clientLocked(driverID); c != nil {
+		driverName = c.Name
+	}
+	r.sendLocked(r.pendingDriverID, ControlDeniedEvent{ClientID: driverID, Username: driverName})
+	r.pendingDriverID = ""
+}
+
+// ReleaseControl gives up the keyboard, passing it to a pending requester if any.
+func (r *Room) ReleaseControl(clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.driverID != clientID {
+		return
+	}
+	// giving up the keyboard in a mob ends your turn early
+	if r.mob != nil && r.pendingDriverID == "" {
+		r.rotateLocked(clientID)
+		return
+	}
+	next := r.pendingDriverID
+	r.pendingDriverID = ""
+	r.passKeyboardLocked(next)
+}
+
+// ReleaseIdleDriver frees the keyboard if the driver has stopped typing.
+// Safe to call from every client's tick; only the first call does anything.
+func (r *Room) ReleaseIdleDriver() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.driverID == "" || r.mob != nil || time.Since(r.lastInput) < DriverIdleTimeout {
+		return
+	}
+	next := r.pendingDriverID
+	r.pendingDriverID = ""
+	r.setDriverLocked(next)
+}
+
+// passKeyboardLocked gives next the keyboard outside the usual rotation.
+// In a mob their turn starts now: they move to the front of the queue and
+// whoever was driving goes to the back, so queue[0] stays the driver.
+// Callers must hold r.mu
+func (r *Room) passKeyboardLocked(next string) {
+	if r.mob == nil || next == "" {
+		r.setDriverLocked(next)
+		return
+	}
+	q := slices.DeleteFunc(slices.Clone(r.mob.queue), func(id string) bool { return id == next })
+	if len(q) > 0 && q[0] == r.driverID {
+		q = append(q[1:], q[0])
+	}
+	r.mob.queue = append([]string{next}, q...)
+	r.setDriverLocked(next)
+	r.scheduleTurnLocked()
+	r.broadcastLocked(MobEvent{Action: MobQueueChanged, TurnEnds: r.mob.turnEnds}, "")
+}
+
+// setDriverLocked changes the driver and tells everyone; callers must hold r.mu
+func (r *Room) setDriverLocked(clientID string) {
+	r.driverID = clientID
+	r.lastInput = time.Now()
+
+	var username string
+	if c := r.clientLocked(clientID); c != nil {
+		username = c.Name
+	}
+	r.broadcastLocked(DriverEvent{ClientID: clientID, Username: username}, "")
+}
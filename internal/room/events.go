@@ -0,0 +1,306 @@
+package room
+
+import "time"
+
+// RoomEvent is something that happened in a room. The set of events is
+// closed - only the types in this file implement it - so consumers can
+// type-switch over them knowing every case.
+type RoomEvent interface {
+	Header() EventHeader
+	// stamp returns a copy of the event with its header filled in by the log
+	stamp(h EventHeader) RoomEvent
+}
+
+// EventHeader is embedded in every event and filled in when it is logged.
+type EventHeader struct {
+	Seq uint64    // position in the room's event log
+	At  time.Time // when the event was logged
+}
+
+func (h EventHeader) Header() EventHeader { return h }
+
+// JoinEvent: a client joined the room.
+type JoinEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+	Role     Role
+}
+
+// LeaveEvent: a client left or dropped out of the room. Removed is set
+// when it was kicked, banned or the room closed, which was announced
+// separately.
+type LeaveEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+	Removed  bool
+}
+
+// RoleEvent: a client's role changed.
+type RoleEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+	Role     Role
+}
+
+// DriverEvent: the keyboard changed hands. ClientID is empty when it's free.
+type DriverEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+}
+
+// ControlRequestEvent is sent to the driver when someone asks for the keyboard.
+type ControlRequestEvent struct {
+	EventHeader
+	ClientID string // requester
+	Username string
+}
+
+// ControlDeniedEvent is sent to a requester the driver turned down.
+type ControlDeniedEvent struct {
+	EventHeader
+	ClientID string // driver
+	Username string
+}
+
+// ExpiringEvent warns that the room will close at Deadline. A zero Deadline
+// means an earlier warning no longer applies.
+type ExpiringEvent struct {
+	EventHeader
+	Deadline time.Time
+	Reason   string
+}
+
+// ClosedEvent: the room was shut down under its participants.
+type ClosedEvent struct {
+	EventHeader
+	Reason string
+}
+
+// TypingEvent: a client typed into the shared terminal.
+type TypingEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+}
+
+// AISyncEvent carries the AI conversation after a client updated it.
+type AISyncEvent struct {
+	EventHeader
+	Messages []AIMessage
+}
+
+// ChatEvent: someone posted to the room chat.
+type ChatEvent struct {
+	EventHeader
+	Message ChatMessage
+}
+
+// TabAction says what happened to a terminal tab
+type TabAction int
+
+const (
+	TabOpened TabAction = iota
+	TabRenamed
+	TabClosed
+)
+
+// TabEvent: a terminal tab was opened, renamed or closed.
+type TabEvent struct {
+	EventHeader
+	Action   TabAction
+	TabID    string
+	Name     string
+	ClientID string // who did it
+}
+
+// ViewingEvent: a client switched to a different terminal tab.
+type ViewingEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+	TabID    string
+}
+
+// InterviewAction says what changed in an interview room
+type InterviewAction int
+
+const (
+	InterviewProblemPinned InterviewAction = iota
+	InterviewStarted
+	InterviewEnded
+)
+
+// InterviewEvent: the problem was pinned or the timer started or ended.
+type InterviewEvent struct {
+	EventHeader
+	Action      InterviewAction
+	Deadline    time.Time // when the countdown runs out, for InterviewStarted
+	SummaryPath string    // where the summary was written, for InterviewEnded
+}
+
+// MobAction says what changed in a mob room's rotation
+type MobAction int
+
+const (
+	MobRotated      MobAction = iota // the keyboard passed to the next driver
+	MobQueueChanged                  // someone joined, left or moved in the queue
+)
+
+// MobEvent: the driver rotated or the queue changed.
+type MobEvent struct {
+	EventHeader
+	Action    MobAction
+	DriverID  string // new driver, for MobRotated
+	Driver    string
+	Next      string    // who's up after them
+	SkippedBy string    // set if someone cut the turn short
+	TurnEnds  time.Time // zero while there's nobody to rotate to
+}
+
+// ModerationAction is what the host did to a participant
+type ModerationAction int
+
+const (
+	ModKicked ModerationAction = iota
+	ModBanned
+	ModMuted
+	ModUnmuted
+)
+
+// ModerationEvent: the host kicked, banned, muted or unmuted someone. A
+// kicked or banned client leaves the room when it sees this.
+type ModerationEvent struct {
+	EventHeader
+	Action   ModerationAction
+	ClientID string
+	Username string
+	Reason   string
+}
+
+// HostEvent: the host role moved to another participant, either handed
+// over or because the host left.
+type HostEvent struct {
+	EventHeader
+	ClientID string
+	Username string
+	Previous string // the old host's username
+}
+
+// KnockEvent is sent to the host when someone asks to be let in.
+type KnockEvent struct {
+	EventHeader
+	KnockID  string
+	Username string
+	Identity string
+}
+
+// RecordingEvent: the host started or stopped recording the terminals.
+type RecordingEvent struct {
+	EventHeader
+	Recording bool
+	ClientID  string
+	Username  string
+}
+
+func (e JoinEvent) stamp(h EventHeader) RoomEvent           { e.EventHeader = h; return e }
+func (e LeaveEvent) stamp(h EventHeader) RoomEvent          { e.EventHeader = h; return e }
+func (e RoleEvent) stamp(h EventHeader) RoomEvent           { e.EventHeader = h; return e }
+func (e DriverEvent) stamp(h EventHeader) RoomEvent         { e.EventHeader = h; return e }
+func (e ControlRequestEvent) stamp(h EventHeader) RoomEvent { e.EventHeader = h; return e }
+func (e ControlDeniedEvent) stamp(h EventHeader) RoomEvent  { e.EventHeader = h; return e }
+func (e ExpiringEvent) stamp(h EventHeader) RoomEvent       { e.EventHeader = h; return e }
+func (e ClosedEvent) stamp(h EventHeader) RoomEvent         { e.EventHeader = h; return e }
+func (e TypingEvent) stamp(h EventHeader) RoomEvent         { e.EventHeader = h; return e }
+func (e AISyncEvent) stamp(h EventHeader) RoomEvent         { e.EventHeader = h; return e }
+func (e ChatEvent) stamp(h EventHeader) RoomEvent           { e.EventHeader = h; return e }
+func (e TabEvent) stamp(h EventHeader) RoomEvent            { e.EventHeader = h; return e }
+func (e ViewingEvent) stamp(h EventHeader) RoomEvent        { e.EventHeader = h; return e }
+func (e InterviewEvent) stamp(h EventHeader) RoomEvent      { e.EventHeader = h; return e }
+func (e MobEvent) stamp(h EventHeader) RoomEvent            { e.EventHeader = h; return e }
+func (e ModerationEvent) stamp(h EventHeader) RoomEvent     { e.EventHeader = h; return e }
+func (e HostEvent) stamp(h EventHeader) RoomEvent           { e.EventHeader = h; return e }
+func (e KnockEvent) stamp(h EventHeader) RoomEvent          { e.EventHeader = h; return e }
+func (e RecordingEvent) stamp(h EventHeader) RoomEvent      { e.EventHeader = h; return e }
+
+// eventLogSize is how many events a room remembers. A client whose cursor
+// falls further behind than this gets a full resync instead.
+const eventLogSize = 256
+
+// logEntry is an event in the room log along with who should see it
+type logEntry struct {
+	event   RoomEvent
+	to      string // only this client, if set
+	exclude string // everyone but this client, if set
+}
+
+func (e logEntry) visibleTo(clientID string) bool {
+	if e.to != "" {
+		return e.to == clientID
+	}
+	return e.exclude != clientID
+}
+
+// appendLocked stamps event with the next sequence number, logs it and
+// wakes the clients it's addressed to; callers must hold r.mu
+func (r *Room) appendLocked(entry logEntry) {
+	r.lastSeq++
+	entry.event = entry.event.stamp(EventHeader{Seq: r.lastSeq, At: time.Now()})
+
+	r.eventLog = append(r.eventLog, entry)
+	if len(r.eventLog) > eventLogSize {
+		r.eventLog = r.eventLog[len(r.eventLog)-eventLogSize:]
+	}
+
+	for _, c := range r.Connections {
+		if entry.visibleTo(c.ID) {
+			c.wake()
+		}
+	}
+}
+
+// ReadEvents returns the events clientID hasn't seen yet, in order, and
+// advances its cursor. resync is true when the client fell so far behind
+// that events were dropped from the log; it should then rebuild its view
+// from the room's current state.
+func (r *Room) ReadEvents(clientID string) (events []RoomEvent, resync bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.clientLocked(clientID)
+	if c == nil || c.cursor == r.lastSeq {
+		return nil, false
+	}
+
+	first := r.lastSeq - uint64(len(r.eventLog)) + 1
+	if c.cursor+1 < first {
+		c.cursor = r.lastSeq
+		return nil, true
+	}
+
+	for _, e := range r.eventLog[c.cursor+1-first:] {
+		if e.visibleTo(clientID) {
+			events = append(events, e.event)
+		}
+	}
+	c.cursor = r.lastSeq
+	return events, false
+}
+
+// Notify returns a channel that receives a value whenever the client has
+// unread events, and is closed when the client leaves the room.
+func (c *Client) Notify() <-chan struct{} {
+	return c.notify
+}
+
+// wake signals the client without blocking; pending wakeups coalesce since
+// the events themselves live in the room log
+func (c *Client) wake() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
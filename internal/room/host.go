@@ -0,0 +1,106 @@
+package room
+
+import (
+	"slices"
+	"time"
+)
+
+// HostName returns the username of the room's current host.
+func (r *Room) HostName() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Host
+}
+
+// TransferHost hands the host role to another participant. The old host
+// becomes an ordinary participant for the room's mode.
+func (r *Room) TransferHost(hostID, targetID string) error {
+	r.mu.Lock()
+	target, err := r.moderateLocked(hostID, targetID)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	prev := r.clientLocked(hostID)
+	prev.Role = r.participantRoleLocked()
+	if r.driverID == prev.ID && !prev.Role.CanWrite() {
+		r.setDriverLocked("")
+	}
+	r.promoteLocked(target, prev.Name)
+	r.mu.Unlock()
+
+	return r.persist()
+}
+
+// hostConnectedLocked reports whether the host is in the room; callers
+// must hold r.mu
+func (r *Room) hostConnectedLocked() bool {
+	return slices.ContainsFunc(r.Connections, (*Client).IsHost)
+}
+
+// hostSeatHeldLocked reports whether a dropped host's seat is still
+// waiting for them to reconnect; callers must hold r.mu
+func (r *Room) hostSeatHeldLocked() bool {
+	now := time.Now()
+	for _, s := range r.seats {
+		if s.role == RoleHost && now.Before(s.expires) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostExpectedLocked reports whether the room has a host, in the room or
+// due back from a dropped connection; callers must hold r.mu
+func (r *Room) hostExpectedLocked() bool {
+	return r.hostConnectedLocked() || r.hostSeatHeldLocked()
+}
+
+// succeedHostLocked picks a new host when the current one leaves: whoever
+// has been connected longest, passing over an interview's candidate.
+// Reports whether the host changed; callers must hold r.mu.
+func (r *Room) succeedHostLocked(prevName string) bool {
+	var next *Client
+	for _, c := range r.Connections {
+		if r.interview != nil && c.ID == r.interview.candidateID {
+			continue
+		}
+		if next == nil || c.joinedAt.Before(next.joinedAt) {
+			next = c
+		}
+	}
+	if next == nil {
+		return false
+	}
+	r.promoteLocked(next, prevName)
+	return true
+}
+
+// promoteLocked makes c the host and tells everyone. Seats held for a
+// dropped host are downgraded so they can't come back as a second one;
+// callers must hold r.mu.
+func (r *Room) promoteLocked(c *Client, prevName string) {
+	c.Role = RoleHost
+	r.Host = c.Name
+	for identity, s := range r.seats {
+		if s.role == RoleHost {
+			s.role = r.participantRoleLocked()
+			r.seats[identity] = s
+		}
+	}
+	r.broadcastLocked(HostEvent{ClientID: c.ID, Username: c.Name, Previous: prevName}, "")
+	r.forwardKnocksLocked(c.ID)
+	if r.mob != nil {
+		r.joinMobLocked(c)
+	}
+}
+
+// participantRoleLocked is the role a non-host has in this room's mode;
+// callers must hold r.mu
+func (r *Room) participantRoleLocked() Role {
+	switch r.Mode {
+	case ModeInterview, ModeClassroom:
+		return RoleObserver
+	}
+	return RoleDriver
+}
@@ -0,0 +1,305 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotHost             = errors.New("only the host can do that")
+	ErrNotInterview        = errors.New("not an interview room")
+	ErrInterviewNotRunning = errors.New("interview timer isn't running")
+)
+
+// interviewState tracks an interview room's problem, timer and what the
+// candidate did, for the summary written when it ends.
+type interviewState struct {
+	candidateID string
+	candidate   string
+	problem     string // markdown
+
+	started  time.Time
+	deadline time.Time
+	ended    time.Time
+
+	commands      []string
+	lineBufs      map[string][]byte // clientID -> partially typed line
+	aiPromptStart int               // len(AIMessages) when the timer started
+
+	summary string // markdown summary of the last interview, for the host
+}
+
+// InterviewStatus reports the interview timer. running is false before it
+// starts and after it ends.
+func (r *Room) InterviewStatus() (started, deadline time.Time, running bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	iv := r.interview
+	if iv == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return iv.started, iv.deadline, !iv.started.IsZero() && iv.ended.IsZero()
+}
+
+// Problem returns the pinned problem statement, if any.
+func (r *Room) Problem() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.interview == nil {
+		return ""
+	}
+	return r.interview.problem
+}
+
+// Summary returns the markdown summary of the last interview to end, or
+// "" if there isn't one yet. Only the host can read it.
+func (r *Room) Summary(clientID string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := r.requireHostLocked(clientID); err != nil {
+		return "", err
+	}
+	if r.interview == nil {
+		return "", ErrNotInterview
+	}
+	return r.interview.summary, nil
+}
+
+// PinProblem sets the markdown problem statement everyone sees.
+func (r *Room) PinProblem(clientID, markdown string) error {
+	r.mu.Lock()
+	if err := r.requireHostLocked(clientID); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	if r.interview == nil {
+		r.mu.Unlock()
+		return ErrNotInterview
+	}
+	r.interview.problem = markdown
+	r.broadcastLocked(InterviewEvent{Action: InterviewProblemPinned}, "")
+	r.mu.Unlock()
+
+	return r.persist()
+}
+
+// ProblemDir is where problem statement files are read from: the
+// template's working directory, or "" if there isn't one.
+func (r *Room) ProblemDir() string {
+	if r.template != nil {
+		return r.template.Dir
+	}
+	return ""
+}
+
+// StartInterview starts the countdown. The interview ends by itself when
+// it runs out.
+func (r *Room) StartInterview(clientID string, length time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.requireHostLocked(clientID); err != nil {
+		return err
+	}
+	iv := r.interview
+	if iv == nil {
+		return ErrNotInterview
+	}
+
+	iv.started = time.Now()
+	iv.deadline = iv.started.Add(length)
+	iv.ended = time.Time{}
+	iv.commands = nil
+	iv.lineBufs = make(map[string][]byte)
+	iv.aiPromptStart = len(r.AIMessages)
+	iv.summary = ""
+
+	started := iv.started
+	time.AfterFunc(length, func() { r.timeUp(started) })
+
+	r.broadcastLocked(InterviewEvent{Action: InterviewStarted, Deadline: iv.deadline}, "")
+	return nil
+}
+
+// EndInterview stops the timer and writes the session summary, returning its path.
+func (r *Room) EndInterview(clientID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.requireHostLocked(clientID); err != nil {
+		return "", err
+	}
+	return r.endInterviewLocked()
+}
+
+// RecordCommand notes a command run outside the shared terminal, e.g. in
+// the sandbox, for the interview summary.
+func (r *Room) RecordCommand(clientID, cmd string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.interviewRunningLocked() {
+		r.interview.commands = append(r.interview.commands, r.usernameLocked(clientID)+" (sandbox): "+cmd)
+	}
+}
+
+// timeUp ends the interview the timer was started for, unless it was
+// already ended or restarted
+func (r *Room) timeUp(started time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.interview == nil || !r.interview.started.Equal(started) || !r.interviewRunningLocked() {
+		return
+	}
+	r.endInterviewLocked()
+}
+
+// endInterviewLocked writes the summary, keeping it for the host to read
+// in the room, and tells everyone; callers must hold r.mu
+func (r *Room) endInterviewLocked() (string, error) {
+	if !r.interviewRunningLocked() {
+		return "", ErrInterviewNotRunning
+	}
+	r.interview.ended = time.Now()
+	r.interview.summary = r.summaryLocked()
+
+	path, err := r.writeSummaryLocked()
+	r.broadcastLocked(InterviewEvent{Action: InterviewEnded, SummaryPath: path}, "")
+	return path, err
+}
+
+// recordInputLocked collects typed lines as commands while the interview
+// runs; callers must hold r.mu
+func (r *Room) recordInputLocked(clientID string, data []byte) {
+	if !r.interviewRunningLocked() {
+		return
+	}
+
+	iv := r.interview
+	buf := iv.lineBufs[clientID]
+	for i := 0; i < len(data); i++ {
+		switch b := data[i]; {
+		case b == '\r' || b == '\n':
+			if line := strings.TrimSpace(string(buf)); line != "" {
+				iv.commands = append(iv.commands, r.usernameLocked(clientID)+": "+line)
+			}
+			buf = buf[:0]
+		case b == 127 || b == '\b':
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+			}
+		case b == 0x1b:
+			// skip escape sequences (arrow keys etc.) - we can't follow
+			// line editing, so the summary shows what was typed
+			i = len(data)
+		case b >= 0x20:
+			buf = append(buf, b)
+		}
+	}
+	iv.lineBufs[clientID] = buf
+}
+
+// assignInterviewRoleLocked makes the first non-host joiner the candidate
+// and everyone after an observer; callers must hold r.mu
+func (r *Room) assignInterviewRoleLocked(client *Client) {
+	if r.interview == nil || client.Role == RoleHost {
+		return
+	}
+	if r.interview.candidateID == "" {
+		r.interview.candidateID = client.ID
+		r.interview.candidate = client.Name
+	}
+	if client.ID == r.interview.candidateID {
+		client.Role = RoleDriver
+		return
+	}
+	client.Role = RoleObserver
+}
+
+func (r *Room) interviewRunningLocked() bool {
+	return r.interview != nil && !r.interview.started.IsZero() && r.interview.ended.IsZero()
+}
+
+// requireHostLocked checks clientID is the room's host; callers must hold r.mu
+func (r *Room) requireHostLocked(clientID string) error {
+	if c := r.clientLocked(clientID); c == nil || !c.IsHost() {
+		return ErrNotHost
+	}
+	return nil
+}
+
+// usernameLocked returns a connected client's name; callers must hold r.mu
+func (r *Room) usernameLocked(clientID string) string {
+	if c := r.clientLocked(clientID); c != nil {
+		return c.Name
+	}
+	return "unknown"
+}
+
+// summaryLocked renders the interview summary as markdown; callers must
+// hold r.mu
+func (r *Room) summaryLocked() string {
+	iv := r.interview
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Interview summary: %s\n\n", r.ID)
+	if r.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", r.Description)
+	}
+	fmt.Fprintf(&b, "- Interviewer: %s\n", r.Host)
+	fmt.Fprintf(&b, "- Candidate: %s\n", iv.candidate)
+	fmt.Fprintf(&b, "- Started: %s\n", iv.started.Format(time.RFC1123))
+	fmt.Fprintf(&b, "- Time used: %s of %s\n",
+		iv.ended.Sub(iv.started).Round(time.Second),
+		iv.deadline.Sub(iv.started).Round(time.Second))
+
+	if iv.problem != "" {
+		b.WriteString("\n## Problem\n\n" + iv.problem + "\n")
+	}
+
+	b.WriteString("\n## Commands run\n\n")
+	if len(iv.commands) == 0 {
+		b.WriteString("_none_\n")
+	}
+	for _, c := range iv.commands {
+		fmt.Fprintf(&b, "    %s\n", c)
+	}
+
+	b.WriteString("\n## AI prompts\n\n")
+	var prompts int
+	if iv.aiPromptStart <= len(r.AIMessages) {
+		for _, msg := range r.AIMessages[iv.aiPromptStart:] {
+			if msg.Role == "user" {
+				fmt.Fprintf(&b, "- **%s**: %s\n", msg.UserID, msg.Text)
+				prompts++
+			}
+		}
+	}
+	if prompts == 0 {
+		b.WriteString("_none_\n")
+	}
+	return b.String()
+}
+
+// writeSummaryLocked writes the interview summary under r.summaryDir;
+// callers must hold r.mu
+func (r *Room) writeSummaryLocked() (string, error) {
+	iv := r.interview
+	if err := os.MkdirAll(r.summaryDir, 0o700); err != nil {
+		return "", fmt.Errorf("create summary dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.md", r.ID, iv.ended.Format("20060102-150405"))
+	path := filepath.Join(r.summaryDir, name)
+	if err := os.WriteFile(path, []byte(iv.summary), 0o600); err != nil {
+		return "", fmt.Errorf("write summary: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,133 @@
+package room
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var ErrKnockNotFound = errors.New("nobody is waiting with that request")
+
+// Knock is someone waiting to be let into a room that needs the host's
+// approval.
+type Knock struct {
+	ID       string
+	Username string
+	Identity string // key fingerprint, or "user:<name>" for keyless logins
+
+	answer chan bool
+}
+
+// Answer delivers the host's decision: true to come in. It is closed
+// without a value if the room goes away first.
+func (k *Knock) Answer() <-chan bool {
+	return k.answer
+}
+
+// NeedsApproval reports whether identity has to knock to get in. Nobody
+// does once there's no host to answer, connected or holding a seat; the
+// next one in becomes host.
+func (r *Room) NeedsApproval(identity string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Approval && !r.approved[identity] && r.hostExpectedLocked()
+}
+
+// Knock asks the host to let username in and tells any connected host.
+func (r *Room) Knock(username, identity string) *Knock {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := &Knock{ID: uuid.New().String(), Username: username, Identity: identity, answer: make(chan bool, 1)}
+	r.knocks = append(r.knocks, k)
+	r.notifyHostsLocked(KnockEvent{KnockID: k.ID, Username: username, Identity: identity})
+	return k
+}
+
+// Knocks returns the people waiting to be let in, oldest first.
+func (r *Room) Knocks() []Knock {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	knocks := make([]Knock, len(r.knocks))
+	for i, k := range r.knocks {
+		knocks[i] = Knock{ID: k.ID, Username: k.Username, Identity: k.Identity}
+	}
+	return knocks
+}
+
+// AnswerKnock lets a waiting person in, or turns them away. Once let in,
+// they can come back without knocking for the life of the room, unless
+// they logged in without a key, as anyone can claim that username.
+func (r *Room) AnswerKnock(hostID, knockID string, approve bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.requireHostLocked(hostID); err != nil {
+		return err
+	}
+	i := slices.IndexFunc(r.knocks, func(k *Knock) bool { return k.ID == knockID })
+	if i < 0 {
+		return ErrKnockNotFound
+	}
+	k := r.knocks[i]
+	r.knocks = slices.Delete(r.knocks, i, i+1)
+
+	if approve && keyed(k.Identity) {
+		if r.approved == nil {
+			r.approved = make(map[string]bool)
+		}
+		r.approved[k.Identity] = true
+	}
+	k.answer <- approve
+	close(k.answer)
+	return nil
+}
+
+// CancelKnock withdraws a request when the person gives up waiting.
+func (r *Room) CancelKnock(knockID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.knocks = slices.DeleteFunc(r.knocks, func(k *Knock) bool { return k.ID == knockID })
+}
+
+// openDoorLocked lets everyone waiting in once the host has gone for good
+// with nobody to take over, as they wouldn't have had to knock now;
+// callers must hold r.mu
+func (r *Room) openDoorLocked() {
+	for _, k := range r.knocks {
+		k.answer <- true
+		close(k.answer)
+	}
+	r.knocks = nil
+}
+
+// forwardKnocksLocked tells a new host about everyone still waiting for
+// the last one; callers must hold r.mu
+func (r *Room) forwardKnocksLocked(hostID string) {
+	for _, k := range r.knocks {
+		r.sendLocked(hostID, KnockEvent{KnockID: k.ID, Username: k.Username, Identity: k.Identity})
+	}
+}
+
+// closeKnocks turns everyone waiting away when the room closes
+func (r *Room) closeKnocks() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.knocks {
+		close(k.answer)
+	}
+	r.knocks = nil
+}
+
+// notifyHostsLocked sends event to the connected host; callers must hold r.mu
+func (r *Room) notifyHostsLocked(event RoomEvent) {
+	for _, c := range r.Connections {
+		if c.IsHost() {
+			r.sendLocked(c.ID, event)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package room
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// RoomSummary is what the lobby shows about a public room.
+type RoomSummary struct {
+	ID           string
+	Description  string
+	Host         string
+	Tags         []string
+	Mode         Mode
+	Participants int
+	CreatedAt    time.Time
+	Protected    bool // joining needs a password or invite
+}
+
+// PublicRooms lists the rooms marked public, newest first.
+func (m *Manager) PublicRooms() []RoomSummary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rooms []RoomSummary
+	for _, r := range m.rooms {
+		if !r.Public {
+			continue
+		}
+		rooms = append(rooms, RoomSummary{
+			ID:           r.ID,
+			Description:  r.Description,
+			Host:         r.HostName(),
+			Tags:         r.Tags,
+			Mode:         r.Mode,
+			Participants: r.ClientCount(),
+			CreatedAt:    r.CreatedAt,
+			Protected:    r.HasSecret(),
+		})
+	}
+	slices.SortFunc(rooms, func(a, b RoomSummary) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return rooms
+}
+
+// ParseTags splits a comma or space separated list into lowercase tags,
+// dropping duplicates.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		t = strings.TrimPrefix(t, "#")
+		if t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
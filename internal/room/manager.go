@@ -3,44 +3,205 @@ package room
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
 	ErrRoomNotFound = errors.New("room not found")
+	ErrNotInRoom    = errors.New("not connected to this room")
 )
 
+// Config tunes room lifetimes and setup. The zero value tears rooms down as
+// soon as they empty and starts bare shells.
+type Config struct {
+	// ReconnectGrace is how long an emptied room keeps its terminal, and a
+	// dropped participant keeps their seat, waiting for a reconnect.
+	ReconnectGrace time.Duration
+
+	// IdleTimeout closes rooms with no input, terminal output or AI use
+	// for this long. Zero disables it.
+	IdleTimeout time.Duration
+
+	// MaxAge closes rooms this long after creation regardless of activity.
+	// Zero disables it.
+	MaxAge time.Duration
+
+	// ExpiryWarning is how far ahead participants are warned before a room
+	// is closed. Defaults to a minute.
+	ExpiryWarning time.Duration
+
+	// Templates are the room setups offered on the create screen.
+	Templates []Template
+
+	// SummaryDir is where interview summaries are written.
+	SummaryDir string
+
+	// RecordingDir is where terminal recordings are written, in a
+	// directory per room.
+	RecordingDir string
+}
+
+// RoomOptions are what the host picks when creating a room.
+type RoomOptions struct {
+	Description string
+	// Secret, if set, must be presented (or an invite token) to OpenRoom.
+	Secret string
+	// Template names one of Config.Templates; empty starts a bare shell.
+	Template string
+	Mode     Mode
+	// Public rooms are listed in the lobby with their tags.
+	Public bool
+	Tags   []string
+	// Approval makes joiners knock and wait for the host to let them in.
+	Approval bool
+}
+
 type Manager struct {
-	rooms map[string]*Room
-	mu    sync.RWMutex
+	rooms   map[string]*Room
+	aliases map[string]string // legacy UUID -> room code
+	store   RoomStore
+	cfg     Config
+	closing bool
+	done    chan struct{} // closed to stop the reaper
+	mu      sync.RWMutex
+
+	joinFailures *failureLimiter // by identity: unknown codes and wrong passwords
+	roomFailures *failureLimiter // by room UUID: wrong passwords
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		rooms: make(map[string]*Room),
+// NewManager creates a manager backed by store and reloads any rooms it holds.
+// A nil store keeps rooms in memory only.
+func NewManager(store RoomStore, cfg Config) (*Manager, error) {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	m := &Manager{
+		rooms:   make(map[string]*Room),
+		aliases: make(map[string]string),
+		store:   store,
+		cfg:     cfg,
+		done:    make(chan struct{}),
+
+		joinFailures: newFailureLimiter(maxJoinFailures),
+		roomFailures: newFailureLimiter(maxRoomFailures),
 	}
+
+	records, err := store.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		// a template removed from config since just means a bare shell
+		tmpl, _ := m.template(rec.Template)
+		m.rooms[rec.ID] = &Room{
+			Template:     rec.Template,
+			template:     tmpl,
+			Mode:         rec.Mode,
+			Public:       rec.Public,
+			Tags:         rec.Tags,
+			Approval:     rec.Approval,
+			summaryDir:   m.cfg.SummaryDir,
+			recordingDir: m.cfg.RecordingDir,
+			ID:           rec.ID,
+			UUID:         rec.UUID,
+			Description:  rec.Description,
+			Host:         rec.Host,
+			CreatedAt:    rec.CreatedAt,
+			Connections:  make([]*Client, 0),
+			AIMessages:   rec.AIMessages,
+			Chat:         rec.Chat,
+			// restored rooms get a fresh idle window so people can find them again
+			lastActivity: time.Now(),
+			secretHash:   rec.SecretHash,
+			bans:         rec.Bans,
+			store:        store,
+		}
+		switch rec.Mode {
+		case ModeInterview:
+			m.rooms[rec.ID].interview = &interviewState{problem: rec.Problem}
+		case ModeMob:
+			m.rooms[rec.ID].mob = &mobState{rotation: rec.Rotation}
+		}
+		if rec.UUID != "" {
+			m.aliases[rec.UUID] = rec.ID
+		}
+	}
+
+	if cfg.IdleTimeout > 0 || cfg.MaxAge > 0 {
+		go m.reapLoop()
+	}
+	return m, nil
 }
 
-func (m *Manager) CreateRoom(host, description string) (*Room, error) {
+// CreateRoom creates a room hosted by host.
+func (m *Manager) CreateRoom(host string, opts RoomOptions) (*Room, error) {
+	// before taking m.mu, as bcrypt takes a while
+	secretHash, err := hashSecret(opts.Secret)
+	if err != nil {
+		return nil, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	roomID := uuid.New().String()
+	tmpl, err := m.template(opts.Template)
+	if err != nil {
+		return nil, err
+	}
+	description := opts.Description
+	if description == "" && tmpl != nil {
+		description = tmpl.Description
+	}
+
+	roomID := generateCode(func(code string) bool {
+		_, exists := m.rooms[code]
+		return exists || m.store.Has(code)
+	})
 	room := &Room{
-		ID:          roomID,
-		Description: description,
-		Host:        host,
-		Connections: make([]*Client, 0),
+		ID:           roomID,
+		UUID:         uuid.New().String(),
+		Description:  description,
+		Host:         host,
+		CreatedAt:    time.Now(),
+		Connections:  make([]*Client, 0),
+		Template:     opts.Template,
+		template:     tmpl,
+		Mode:         opts.Mode,
+		Public:       opts.Public,
+		Tags:         opts.Tags,
+		Approval:     opts.Approval,
+		summaryDir:   m.cfg.SummaryDir,
+		recordingDir: m.cfg.RecordingDir,
+		store:        m.store,
+		lastActivity: time.Now(),
+		secretHash:   secretHash,
+	}
+	switch opts.Mode {
+	case ModeInterview:
+		room.interview = &interviewState{}
+		if tmpl != nil {
+			room.interview.problem = tmpl.Problem
+		}
+	case ModeMob:
+		room.mob = &mobState{rotation: DefaultRotation}
+	}
+	if err := room.persist(); err != nil {
+		return nil, err
 	}
 	m.rooms[roomID] = room
+	m.aliases[room.UUID] = roomID
 	return room, nil
 }
+
+// GetRoom looks a room up by its code, case-insensitively, or by its UUID alias.
 func (m *Manager) GetRoom(roomID string) (*Room, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	room, exists := m.rooms[roomID]
+	room, exists := m.lookup(roomID)
 	if !exists {
 		return nil, ErrRoomNotFound
 	}
@@ -48,6 +209,45 @@ func (m *Manager) GetRoom(roomID string) (*Room, error) {
 	return room, nil
 }
 
+// OpenRoom looks up a room for username and identity to join, turning
+// away anyone banned before checking credential against the room's secret
+// or outstanding invite tokens. Unknown codes and wrong passwords count
+// against addr, the client's IP address, as anyone can make up a key or
+// username; wrong passwords count against the room too. Past the limit it
+// returns ErrTooManyAttempts for a while.
+func (m *Manager) OpenRoom(roomID, credential, username, identity, addr string) (*Room, error) {
+	if m.joinFailures.blocked(addr) {
+		return nil, ErrTooManyAttempts
+	}
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		m.joinFailures.fail(addr)
+		return nil, err
+	}
+	if err := room.CheckBanned(username, identity); err != nil {
+		return nil, err
+	}
+	err = room.authorize(credential, !m.roomFailures.blocked(room.UUID))
+	if errors.Is(err, ErrInvalidCredentials) {
+		m.joinFailures.fail(addr)
+		m.roomFailures.fail(room.UUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// lookup resolves a code or alias to a room; callers must hold m.mu
+func (m *Manager) lookup(roomID string) (*Room, bool) {
+	key := NormalizeCode(roomID)
+	if code, ok := m.aliases[key]; ok {
+		key = code
+	}
+	room, exists := m.rooms[key]
+	return room, exists
+}
+
 func (m *Manager) RoomCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -58,20 +258,50 @@ func (m *Manager) LeaveRoom(roomID, clientID string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	room, exists := m.rooms[roomID]
+	room, exists := m.lookup(roomID)
 	if !exists {
 		return false
 	}
 
 	room.RemoveClient(clientID)
+	room.closeShell(clientID)
 
 	if room.ClientCount() == 0 {
-		if room.Terminal != nil {
-			room.Terminal.Close()
-			room.Terminal = nil
-		}
-		delete(m.rooms, roomID)
+		m.closeRoomLocked(room)
 		return true
 	}
 	return false
 }
+
+// closeRoomLocked kills the room's terminals and forgets it; callers must hold m.mu
+func (m *Manager) closeRoomLocked(room *Room) {
+	room.closeTabs()
+	room.stopMob()
+	room.closeKnocks()
+	delete(m.rooms, room.ID)
+	delete(m.aliases, room.UUID)
+	// on shutdown sessions drain after Close; keep their rooms on disk
+	if !m.closing {
+		m.store.Delete(room.ID)
+	}
+}
+
+// Close flushes every room to the store and stops rooms from being deleted
+// from it as their sessions drain during shutdown.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.closing {
+		close(m.done)
+	}
+	m.closing = true
+
+	var errs []error
+	for _, room := range m.rooms {
+		if err := room.persist(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,198 @@
+package room
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrNotMob = errors.New("not a mob room")
+
+// DefaultRotation is how long each turn at the keyboard lasts in a mob
+// room until the host changes it.
+const DefaultRotation = 10 * time.Minute
+
+// mobState is a mob room's driver rotation. queue[0] is driving; the rest
+// take over in order.
+type mobState struct {
+	rotation time.Duration
+	queue    []string // client IDs
+	turnEnds time.Time
+	timer    *time.Timer
+}
+
+// MobTurn is one place in the driver queue.
+type MobTurn struct {
+	ClientID string
+	Username string
+}
+
+// MobStatus returns the driver queue, starting with whoever is driving,
+// and when the current turn ends. turnEnds is zero while there's nobody
+// to rotate to.
+func (r *Room) MobStatus() (queue []MobTurn, turnEnds time.Time, rotation time.Duration) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.mob == nil {
+		return nil, time.Time{}, 0
+	}
+	for _, id := range r.mob.queue {
+		queue = append(queue, MobTurn{ClientID: id, Username: r.usernameLocked(id)})
+	}
+	return queue, r.mob.turnEnds, r.mob.rotation
+}
+
+// SetRotation changes how long turns last and restarts the current one.
+func (r *Room) SetRotation(clientID string, rotation time.Duration) error {
+	r.mu.Lock()
+	if err := r.requireHostLocked(clientID); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	if r.mob == nil {
+		r.mu.Unlock()
+		return ErrNotMob
+	}
+	r.mob.rotation = rotation
+	r.scheduleTurnLocked()
+	r.broadcastLocked(MobEvent{Action: MobQueueChanged, TurnEnds: r.mob.turnEnds}, "")
+	r.mu.Unlock()
+
+	return r.persist()
+}
+
+// SkipTurn hands the keyboard to the next person in the queue now.
+func (r *Room) SkipTurn(clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mob == nil {
+		return ErrNotMob
+	}
+	if !slices.Contains(r.mob.queue, clientID) {
+		return ErrReadOnly
+	}
+	r.rotateLocked(clientID)
+	return nil
+}
+
+// MoveInQueue moves clientID delta places later (or earlier, if negative)
+// among those waiting for a turn. The current driver stays put.
+func (r *Room) MoveInQueue(clientID string, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mob == nil {
+		return ErrNotMob
+	}
+	q := r.mob.queue
+	i := slices.Index(q, clientID)
+	if i < 0 {
+		return ErrReadOnly
+	}
+	j := min(max(i+delta, 1), len(q)-1)
+	if i == 0 || i == j {
+		return nil
+	}
+	q = slices.Delete(q, i, i+1)
+	r.mob.queue = slices.Insert(q, j, clientID)
+	r.broadcastLocked(MobEvent{Action: MobQueueChanged, TurnEnds: r.mob.turnEnds}, "")
+	return nil
+}
+
+// joinMobLocked puts a new writer at the back of the queue, giving them
+// the keyboard if they're the only one; callers must hold r.mu
+func (r *Room) joinMobLocked(client *Client) {
+	if r.mob == nil || !client.Role.CanWrite() || slices.Contains(r.mob.queue, client.ID) {
+		return
+	}
+	r.mob.queue = append(r.mob.queue, client.ID)
+	if len(r.mob.queue) == 1 {
+		r.setDriverLocked(client.ID)
+	}
+	if r.mob.turnEnds.IsZero() {
+		r.scheduleTurnLocked()
+	}
+	r.broadcastLocked(MobEvent{Action: MobQueueChanged, TurnEnds: r.mob.turnEnds}, "")
+}
+
+// leaveMobLocked takes a departing client out of the queue, passing the
+// keyboard on if it was their turn; callers must hold r.mu
+func (r *Room) leaveMobLocked(clientID string) {
+	i := slices.Index(r.mob.queue, clientID)
+	if i < 0 {
+		return
+	}
+	r.mob.queue = slices.Delete(r.mob.queue, i, i+1)
+	if i == 0 {
+		next := ""
+		if len(r.mob.queue) > 0 {
+			next = r.mob.queue[0]
+		}
+		r.setDriverLocked(next)
+		r.scheduleTurnLocked()
+	} else if len(r.mob.queue) < 2 {
+		r.scheduleTurnLocked()
+	}
+	r.broadcastLocked(MobEvent{Action: MobQueueChanged, TurnEnds: r.mob.turnEnds}, "")
+}
+
+// rotateLocked moves the driver to the back of the queue and hands the
+// keyboard to the next in line; callers must hold r.mu
+func (r *Room) rotateLocked(skippedBy string) {
+	q := r.mob.queue
+	if len(q) < 2 {
+		return
+	}
+	r.mob.queue = append(q[1:], q[0])
+	r.setDriverLocked(r.mob.queue[0])
+	r.scheduleTurnLocked()
+
+	ev := MobEvent{
+		Action:   MobRotated,
+		DriverID: r.mob.queue[0],
+		Driver:   r.usernameLocked(r.mob.queue[0]),
+		Next:     r.usernameLocked(r.mob.queue[1]),
+		TurnEnds: r.mob.turnEnds,
+	}
+	if skippedBy != "" {
+		ev.SkippedBy = r.usernameLocked(skippedBy)
+	}
+	r.broadcastLocked(ev, "")
+}
+
+// scheduleTurnLocked starts the clock on the current turn, or stops it
+// when there's nobody to hand over to; callers must hold r.mu
+func (r *Room) scheduleTurnLocked() {
+	if r.mob.timer != nil {
+		r.mob.timer.Stop()
+		r.mob.timer = nil
+	}
+	if len(r.mob.queue) < 2 || r.mob.rotation <= 0 {
+		r.mob.turnEnds = time.Time{}
+		return
+	}
+
+	ends := time.Now().Add(r.mob.rotation)
+	r.mob.turnEnds = ends
+	r.mob.timer = time.AfterFunc(r.mob.rotation, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// a skip or leave since has already moved things on
+		if r.mob != nil && r.mob.turnEnds.Equal(ends) {
+			r.rotateLocked("")
+		}
+	})
+}
+
+// stopMob stops the rotation timer when the room closes
+func (r *Room) stopMob() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mob != nil && r.mob.timer != nil {
+		r.mob.timer.Stop()
+		r.mob.timer = nil
+	}
+}
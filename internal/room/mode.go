@@ -0,0 +1,41 @@
+package room
+
+// Mode is the kind of session a room runs.
+type Mode int
+
+const (
+	ModePair      Mode = iota // everyone drives
+	ModeInterview             // first joiner is the candidate, the rest observe
+	ModeClassroom             // the host presents, students watch and get their own shells
+	ModeMob                   // the keyboard rotates through everyone on a timer
+)
+
+// Modes lists the room modes in the order the create screen offers them.
+var Modes = []Mode{ModePair, ModeInterview, ModeClassroom, ModeMob}
+
+func (m Mode) String() string {
+	switch m {
+	case ModeInterview:
+		return "interview"
+	case ModeClassroom:
+		return "classroom"
+	case ModeMob:
+		return "mob"
+	default:
+		return "pair"
+	}
+}
+
+// assignModeRoleLocked picks a joining client's role for the room's mode;
+// callers must hold r.mu
+func (r *Room) assignModeRoleLocked(client *Client) {
+	switch r.Mode {
+	case ModeInterview:
+		r.assignInterviewRoleLocked(client)
+	case ModeClassroom:
+		// only the instructor types into the shared terminals
+		if client.Role != RoleHost {
+			client.Role = RoleObserver
+		}
+	}
+}
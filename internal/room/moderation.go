@@ -0,0 +1,135 @@
+package room
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrBanned       = errors.New("you're banned from this room")
+	ErrMuted        = errors.New("the host has muted you")
+	ErrCantModerate = errors.New("you can't do that to yourself")
+)
+
+// CheckBanned reports ErrBanned if username or identity (a key
+// fingerprint, or "user:<name>" for keyless logins) is banned.
+func (r *Room) CheckBanned(username, identity string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if slices.Contains(r.bans, "user:"+username) || slices.Contains(r.bans, identity) {
+		return ErrBanned
+	}
+	return nil
+}
+
+// Muted reports whether the host has muted clientID's terminal input.
+func (r *Room) Muted(clientID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.muted[clientID]
+}
+
+// Kick removes a participant straight away. Their session goes back to the
+// launch screen with reason, and their seat isn't held for a reconnect.
+func (r *Room) Kick(hostID, targetID, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, err := r.moderateLocked(hostID, targetID)
+	if err != nil {
+		return err
+	}
+	r.kickedLocked(target.ID)
+	ev := ModerationEvent{Action: ModKicked, ClientID: target.ID, Username: target.Name, Reason: reason}
+	r.broadcastLocked(ev, target.ID)
+	r.evictLocked(target.ID, ev)
+	return nil
+}
+
+// Ban kicks a participant and keeps them out for the rest of the room's
+// life: by key fingerprint if byKey is set and they logged in with one,
+// otherwise by username.
+func (r *Room) Ban(hostID, targetID string, byKey bool, reason string) error {
+	r.mu.Lock()
+	target, err := r.moderateLocked(hostID, targetID)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	ban := "user:" + target.Username
+	if byKey && target.Identity != "" {
+		ban = target.Identity
+	}
+	if !slices.Contains(r.bans, ban) {
+		r.bans = append(r.bans, ban)
+	}
+	r.kickedLocked(target.ID)
+	ev := ModerationEvent{Action: ModBanned, ClientID: target.ID, Username: target.Name, Reason: reason}
+	r.broadcastLocked(ev, target.ID)
+	r.evictLocked(target.ID, ev)
+	r.mu.Unlock()
+
+	return r.persist()
+}
+
+// SetMuted stops (or restarts) a participant's terminal input without
+// removing them. A muted driver loses the keyboard.
+func (r *Room) SetMuted(hostID, targetID string, muted bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, err := r.moderateLocked(hostID, targetID)
+	if err != nil {
+		return err
+	}
+	if r.muted == nil {
+		r.muted = make(map[string]bool)
+	}
+	if muted {
+		r.muted[target.ID] = true
+	} else {
+		delete(r.muted, target.ID)
+	}
+
+	action := ModUnmuted
+	if muted {
+		action = ModMuted
+	}
+	switch {
+	case r.mob != nil && muted:
+		// the rotation skips them until they're unmuted
+		r.leaveMobLocked(target.ID)
+	case r.mob != nil:
+		r.joinMobLocked(target)
+	case muted && r.driverID == target.ID:
+		r.setDriverLocked("")
+	}
+	r.broadcastLocked(ModerationEvent{Action: action, ClientID: target.ID, Username: target.Name}, "")
+	return nil
+}
+
+// moderateLocked checks hostID may act on targetID and returns the
+// target; callers must hold r.mu
+func (r *Room) moderateLocked(hostID, targetID string) (*Client, error) {
+	if err := r.requireHostLocked(hostID); err != nil {
+		return nil, err
+	}
+	if hostID == targetID {
+		return nil, ErrCantModerate
+	}
+	target := r.clientLocked(targetID)
+	if target == nil {
+		return nil, ErrNotInRoom
+	}
+	return target, nil
+}
+
+// kickedLocked marks clientID as removed so a dropped connection doesn't
+// hold a seat for them; callers must hold r.mu
+func (r *Room) kickedLocked(clientID string) {
+	if r.kicked == nil {
+		r.kicked = make(map[string]bool)
+	}
+	r.kicked[clientID] = true
+}
@@ -0,0 +1,51 @@
+package room
+
+import "fmt"
+
+// ParticipantColors is how many distinct colors the UI has for telling
+// participants apart.
+const ParticipantColors = 8
+
+// uniqueNameLocked returns username, or username-2, username-3... if
+// someone connected already goes by it. No spaces, so @mentions still
+// work; callers must hold r.mu.
+func (r *Room) uniqueNameLocked(username string) string {
+	taken := make(map[string]bool, len(r.Connections))
+	for _, c := range r.Connections {
+		taken[c.Name] = true
+	}
+	name := username
+	for n := 2; taken[name]; n++ {
+		name = fmt.Sprintf("%s-%d", username, n)
+	}
+	return name
+}
+
+// freeColorLocked returns the palette slot fewest connected clients are
+// using, lowest first; callers must hold r.mu.
+func (r *Room) freeColorLocked() int {
+	var used [ParticipantColors]int
+	for _, c := range r.Connections {
+		used[c.Color%ParticipantColors]++
+	}
+	best := 0
+	for i, n := range used {
+		if n < used[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// ClientByName returns the connected client going by name, or nil.
+func (r *Room) ClientByName(name string) *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.Connections {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
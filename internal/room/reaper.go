@@ -0,0 +1,117 @@
+package room
+
+import (
+	"time"
+)
+
+const (
+	// reapInterval is how often the reaper looks for rooms to expire
+	reapInterval = 10 * time.Second
+
+	defaultExpiryWarning = time.Minute
+)
+
+// Touch records activity in the room, pushing back its idle deadline.
+func (r *Room) Touch() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastActivity = time.Now()
+}
+
+// LastActivity returns the latest of input, AI use and output in any terminal.
+func (r *Room) LastActivity() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	last := r.lastActivity
+	for _, t := range r.tabs {
+		if out := t.Terminal.LastOutput(); out.After(last) {
+			last = out
+		}
+	}
+	return last
+}
+
+// Expiry returns the deadline participants have been warned about and why,
+// or a zero time if the room isn't about to close.
+func (r *Room) Expiry() (time.Time, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.expiryWarned, r.expiryReason
+}
+
+// deadline returns when the room expires and why, or a zero time if
+// neither limit is configured.
+func (m *Manager) deadline(room *Room) (time.Time, string) {
+	var deadline time.Time
+	var reason string
+
+	if m.cfg.IdleTimeout > 0 {
+		deadline = room.LastActivity().Add(m.cfg.IdleTimeout)
+		reason = "idle"
+	}
+	if m.cfg.MaxAge > 0 {
+		if hard := room.CreatedAt.Add(m.cfg.MaxAge); deadline.IsZero() || hard.Before(deadline) {
+			deadline = hard
+			reason = "max age reached"
+		}
+	}
+	return deadline, reason
+}
+
+// reapLoop expires rooms until the manager is closed
+func (m *Manager) reapLoop() {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.reap(time.Now())
+		}
+	}
+}
+
+// reap warns rooms nearing their deadline and closes the ones past it.
+func (m *Manager) reap(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	warnBefore := m.cfg.ExpiryWarning
+	if warnBefore <= 0 {
+		warnBefore = defaultExpiryWarning
+	}
+
+	for _, room := range m.rooms {
+		deadline, reason := m.deadline(room)
+		if deadline.IsZero() {
+			continue
+		}
+
+		switch {
+		case !now.Before(deadline):
+			room.evictAll(ClosedEvent{Reason: reason})
+			m.closeRoomLocked(room)
+		case deadline.Sub(now) <= warnBefore:
+			room.warnExpiry(deadline, reason)
+		default:
+			room.warnExpiry(time.Time{}, "")
+		}
+	}
+}
+
+// warnExpiry tells participants the room is about to close, or that it no
+// longer is if deadline is zero. Each change is only announced once.
+func (r *Room) warnExpiry(deadline time.Time, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.expiryWarned.Equal(deadline) {
+		return
+	}
+	r.expiryWarned = deadline
+	r.expiryReason = reason
+	r.broadcastLocked(ExpiringEvent{Deadline: deadline, Reason: reason}, "")
+}
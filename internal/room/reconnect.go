@@ -0,0 +1,159 @@
+package room
+
+import (
+	"strings"
+	"time"
+)
+
+// seat remembers a disconnected participant so they can come back as the
+// same client instead of a stranger.
+type seat struct {
+	clientID string
+	role     Role
+	expires  time.Time
+}
+
+// keyed reports whether identity is an SSH key fingerprint. Keyless
+// sessions are identified as "user:<name>", which anyone can log in as, so
+// nothing that identity held can be handed back to them.
+func keyed(identity string) bool {
+	return identity != "" && !strings.HasPrefix(identity, "user:")
+}
+
+// holdSeat keeps clientID's identity and role for identity until grace runs out.
+func (r *Room) holdSeat(identity, clientID string, role Role, grace time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.kicked[clientID] {
+		return
+	}
+	// someone took over as host while they were gone
+	if role == RoleHost {
+		for _, c := range r.Connections {
+			if c.IsHost() && c.ID != clientID {
+				role = r.participantRoleLocked()
+				break
+			}
+		}
+	}
+	if r.seats == nil {
+		r.seats = make(map[string]seat)
+	}
+	r.seats[identity] = seat{clientID: clientID, role: role, expires: time.Now().Add(grace)}
+}
+
+// reclaimSeat hands back a held seat for identity, if there is one that
+// hasn't expired. A seat can only be reclaimed once.
+func (r *Room) reclaimSeat(identity string) (seat, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.seats[identity]
+	if !ok {
+		return seat{}, false
+	}
+	delete(r.seats, identity)
+	if time.Now().After(s.expires) {
+		return seat{}, false
+	}
+	return s, true
+}
+
+// expireSeat drops clientID's seat once its grace period is up without
+// them coming back, and with it their private classroom shell.
+func (r *Room) expireSeat(identity, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// back in, or dropped again since with a fresh grace period
+	if r.clientLocked(clientID) != nil {
+		return
+	}
+	if s, ok := r.seats[identity]; ok {
+		if s.clientID == clientID && time.Now().Before(s.expires) {
+			return
+		}
+		if s.clientID == clientID {
+			delete(r.seats, identity)
+		}
+	}
+	r.closeShellLocked(clientID)
+	// the host isn't coming back; nobody is left to answer the door
+	if len(r.Connections) > 0 && !r.hostExpectedLocked() {
+		r.openDoorLocked()
+	}
+}
+
+// DisconnectClient removes a client whose session dropped. Unlike LeaveRoom
+// the room keeps its seat, and its terminal if it is now empty, for the
+// reconnect grace period. Only SSH key logins get their seat held.
+func (m *Manager) DisconnectClient(roomID, clientID, identity string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	room, exists := m.lookup(roomID)
+	if !exists {
+		return
+	}
+
+	role := room.ClientRole(clientID)
+	// the seat is held before the client goes, so a host's room doesn't
+	// open its door or hand over to a stranger in between
+	hold := m.cfg.ReconnectGrace > 0 && keyed(identity)
+	if hold {
+		room.holdSeat(identity, clientID, role, m.cfg.ReconnectGrace)
+	}
+	room.RemoveClient(clientID)
+
+	if m.cfg.ReconnectGrace <= 0 {
+		room.closeShell(clientID)
+		if room.ClientCount() == 0 {
+			m.closeRoomLocked(room)
+		}
+		return
+	}
+
+	if hold {
+		time.AfterFunc(m.cfg.ReconnectGrace, func() { room.expireSeat(identity, clientID) })
+	} else {
+		// nobody can come back to it
+		room.closeShell(clientID)
+	}
+	if room.ClientCount() == 0 {
+		room.graceGen++
+		gen := room.graceGen
+		time.AfterFunc(m.cfg.ReconnectGrace, func() { m.closeIfEmpty(room.ID, gen) })
+	}
+}
+
+// Resume returns the client ID and role a reconnecting identity held in the
+// room, if its seat is still within the grace period.
+func (m *Manager) Resume(roomID, identity string) (clientID string, role Role, ok bool) {
+	if !keyed(identity) {
+		return "", 0, false
+	}
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return "", 0, false
+	}
+	s, ok := room.reclaimSeat(identity)
+	if !ok {
+		return "", 0, false
+	}
+	return s.clientID, s.role, true
+}
+
+// closeIfEmpty tears a room down once its grace period ends, unless
+// someone came back in the meantime. gen is the graceGen the timer was set
+// for; if the room has emptied again since, a later timer owns it.
+func (m *Manager) closeIfEmpty(roomID string, gen int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	room, exists := m.rooms[roomID]
+	if !exists || room.graceGen != gen || room.ClientCount() > 0 {
+		return
+	}
+	m.closeRoomLocked(room)
+}
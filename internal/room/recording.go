@@ -0,0 +1,231 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var ErrReplayNeedsKey = errors.New("log in with an SSH key to watch recordings")
+
+// Recording reports whether the room's terminals are being recorded.
+func (r *Room) Recording() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.recording
+}
+
+// SetRecording starts or stops recording the room's shared terminals as
+// asciicast files under the recording directory, one per tab. Tabs opened
+// while recording are recorded too; private classroom shells never are.
+func (r *Room) SetRecording(hostID string, on bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.requireHostLocked(hostID); err != nil {
+		return err
+	}
+	if r.recording == on {
+		return nil
+	}
+
+	if on {
+		r.recordingName = time.Now().Format("20060102-150405") + "-" + uuid.New().String()[:8]
+		r.recordingTabs = 0
+		r.audience = make(map[string]bool)
+		for _, c := range r.Connections {
+			r.admitLocked(c.Identity)
+		}
+		if err := r.saveSessionLocked(); err != nil {
+			return err
+		}
+		for _, t := range r.tabs {
+			if err := r.recordTabLocked(t); err != nil {
+				r.stopRecordingLocked()
+				return err
+			}
+		}
+	} else {
+		r.stopRecordingLocked()
+		r.audience = nil
+	}
+	r.recording = on
+	r.broadcastLocked(RecordingEvent{Recording: on, ClientID: hostID, Username: r.usernameLocked(hostID)}, "")
+	return nil
+}
+
+// recordTabLocked starts recording one tab to
+// <recordingDir>/<room UUID>/<session>-<n>-<tab>.cast. Codes get reused
+// once a room closes, so they're only used in the title. Tab names can
+// repeat, so n numbers the tabs within the recording; callers must hold r.mu
+func (r *Room) recordTabLocked(t *Tab) error {
+	dir := r.recordingDirLocked()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create recording dir: %w", err)
+	}
+	r.recordingTabs++
+	name := fmt.Sprintf("%s-%d-%s.cast", r.recordingName, r.recordingTabs, fileSafe(t.Name))
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create recording: %w", err)
+	}
+	title := r.ID + ": " + t.Name
+	if err := t.Terminal.StartRecording(f, title); err != nil {
+		f.Close()
+		return err
+	}
+	return nil
+}
+
+// recordingSession is saved beside a recording's .cast files as
+// <session>.json: where it came from and who may watch it back.
+type recordingSession struct {
+	Room        string   `json:"room"`
+	Description string   `json:"description,omitempty"`
+	Audience    []string `json:"audience"`
+}
+
+// admitLocked lets identity watch the current recording back, and reports
+// whether that's new. Keyless identities are just a username anyone can
+// log in with, so they never get access; callers must hold r.mu.
+func (r *Room) admitLocked(identity string) bool {
+	if !keyed(identity) || r.audience[identity] {
+		return false
+	}
+	r.audience[identity] = true
+	return true
+}
+
+// admitToRecordingLocked adds someone let in while recording to the
+// audience; callers must hold r.mu
+func (r *Room) admitToRecordingLocked(identity string) {
+	if r.recording && r.admitLocked(identity) {
+		// the recording carries on either way; they just can't replay it
+		r.saveSessionLocked()
+	}
+}
+
+// saveSessionLocked writes the recording's session file; callers must
+// hold r.mu
+func (r *Room) saveSessionLocked() error {
+	dir := r.recordingDirLocked()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create recording dir: %w", err)
+	}
+	sess := recordingSession{Room: r.ID, Description: r.Description, Audience: slices.Sorted(maps.Keys(r.audience))}
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, r.recordingName+".json")
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("write recording session: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// recordingDirLocked is where this room's recordings go; callers must
+// hold r.mu
+func (r *Room) recordingDirLocked() string {
+	return filepath.Join(r.recordingDir, r.UUID)
+}
+
+// stopRecordingLocked closes every tab's recording; callers must hold r.mu
+func (r *Room) stopRecordingLocked() {
+	for _, t := range r.tabs {
+		t.Terminal.StopRecording()
+	}
+}
+
+// fileSafe turns a tab name into something usable in a file name
+func fileSafe(name string) string {
+	return strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_' {
+			return c
+		}
+		return '-'
+	}, name)
+}
+
+// RecordingInfo is a saved recording of one of a room's terminals.
+type RecordingInfo struct {
+	Room        string // code the room had
+	Description string
+	Name        string // file name: <session>-<n>-<tab>.cast
+	Path        string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Recordings lists the recordings identity may watch, newest first: those
+// made while they were in the room, as its host or someone let in. Only
+// SSH key logins can watch recordings back.
+func (m *Manager) Recordings(identity string) ([]RecordingInfo, error) {
+	if !keyed(identity) {
+		return nil, ErrReplayNeedsKey
+	}
+	rooms, err := os.ReadDir(m.cfg.RecordingDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []RecordingInfo
+	for _, d := range rooms {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(m.cfg.RecordingDir, d.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			session, ok := strings.CutSuffix(e.Name(), ".json")
+			if !ok {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				continue
+			}
+			var sess recordingSession
+			if json.Unmarshal(data, &sess) != nil || !slices.Contains(sess.Audience, identity) {
+				continue
+			}
+			for _, c := range entries {
+				if !strings.HasPrefix(c.Name(), session+"-") || filepath.Ext(c.Name()) != ".cast" {
+					continue
+				}
+				info, err := c.Info()
+				if err != nil {
+					continue
+				}
+				recs = append(recs, RecordingInfo{
+					Room:        sess.Room,
+					Description: sess.Description,
+					Name:        c.Name(),
+					Path:        filepath.Join(dir, c.Name()),
+					Size:        info.Size(),
+					ModTime:     info.ModTime(),
+				})
+			}
+		}
+	}
+	slices.SortFunc(recs, func(a, b RecordingInfo) int {
+		return b.ModTime.Compare(a.ModTime)
+	})
+	return recs, nil
+}
@@ -0,0 +1,107 @@
+package room
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrReadOnly = errors.New("observers can't send input")
+
+// Role is what a participant is allowed to do in a room
+type Role int
+
+const (
+	RoleDriver   Role = iota // can type into the shared terminal and use AI/sandbox
+	RoleObserver             // watch only
+	RoleHost                 // created the room; drives like a driver
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleHost:
+		return "host"
+	case RoleObserver:
+		return "observer"
+	default:
+		return "driver"
+	}
+}
+
+// CanWrite reports whether the role may send input to the terminal, AI or sandbox.
+func (r Role) CanWrite() bool {
+	return r != RoleObserver
+}
+
+// IsHost reports whether the client owns the room.
+func (c *Client) IsHost() bool {
+	return c.Role == RoleHost
+}
+
+// ClientRole returns the role of a connected client, or RoleObserver if it
+// isn't connected - unknown clients never get write access.
+func (r *Room) ClientRole(clientID string) Role {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if c := r.clientLocked(clientID); c != nil {
+		return c.Role
+	}
+	return RoleObserver
+}
+
+// SetRole changes a client's role and tells everyone in the room.
+func (r *Room) SetRole(clientID string, role Role) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.clientLocked(clientID)
+	if c == nil {
+		return
+	}
+	c.Role = role
+
+	r.broadcastLocked(RoleEvent{ClientID: clientID, Username: c.Name, Role: role}, "")
+	switch {
+	case r.mob != nil && role.CanWrite():
+		r.joinMobLocked(c)
+	case r.mob != nil:
+		// observers don't get a turn
+		r.leaveMobLocked(clientID)
+	case !role.CanWrite() && r.driverID == clientID:
+		r.setDriverLocked("")
+	}
+}
+
+// WriteTerminal forwards input from a client to a tab's PTY if its role
+// allows it and it holds (or can claim) the keyboard.
+func (r *Room) WriteTerminal(clientID, tabID string, data []byte) error {
+	if r.Muted(clientID) {
+		return ErrMuted
+	}
+
+	// a classroom student's own shell is theirs alone
+	if shell := r.ownShell(clientID, tabID); shell != nil {
+		r.Touch()
+		_, err := shell.Write(data)
+		return err
+	}
+
+	if !r.ClientRole(clientID).CanWrite() {
+		return ErrReadOnly
+	}
+	if err := r.claimKeyboard(clientID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.lastActivity = time.Now()
+	r.recordInputLocked(clientID, data)
+	r.mu.Unlock()
+
+	tab, err := r.Tab(tabID)
+	if err != nil {
+		return err
+	}
+	_, err = tab.Terminal.Write(data)
+	return err
+}
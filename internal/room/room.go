@@ -1,18 +1,11 @@
 package room
 
 import (
+	"slices"
 	"sync"
-
-	"github.com/jaypopat/duet/internal/terminal"
+	"time"
 )
 
-// RoomEvent represents an event that occurred in a room
-type RoomEvent struct {
-	Type     string
-	Username string
-	Data     string
-}
-
 type AIMessage struct {
 	Role   string `json:"role"`
 	UserID string `json:"user_id"`
@@ -22,87 +15,232 @@ type AIMessage struct {
 
 type Client struct {
 	ID       string
-	Username string
-	IsHost   bool
-	Events   chan RoomEvent
+	Username string // login name; bans match this
+	Name     string // Username made unique in the room, set by AddClient
+	Color    int    // palette slot, set by AddClient; see ParticipantColors
+	Identity string // key fingerprint, or "user:<name>" for keyless logins
+	Role     Role
+	Viewing  string // ID of the terminal tab the client is looking at
+
+	notify   chan struct{} // set up by AddClient
+	cursor   uint64        // last event sequence delivered to this client
+	joinedAt time.Time     // for picking the next host
 }
 
 type Room struct {
-	ID          string
+	ID          string // human-friendly code, e.g. "brave-otter-42"
+	UUID        string // never reused, unlike codes; also accepted by Manager.GetRoom
 	Description string
 	Host        string
+	CreatedAt   time.Time
+	Template    string // name of the template the room was created from, if any
+	Mode        Mode
+	Public      bool     // listed in the lobby
+	Tags        []string // shown and searchable in the lobby
+	Approval    bool     // joiners wait for the host to let them in
 	Connections []*Client
 	mu          sync.RWMutex
-	Terminal    *terminal.Terminal
 	AIMessages  []AIMessage
+	Chat        []ChatMessage
+
+	template *Template // shell setup for new tabs; nil for a bare shell
+
+	interview  *interviewState // set for ModeInterview rooms
+	mob        *mobState       // set for ModeMob rooms
+	summaryDir string          // where interview summaries are written
+
+	recording     bool            // shared tabs are being recorded
+	recordingName string          // <started>-<random>, prefixing this recording's files
+	recordingTabs int             // tabs recorded so far, numbering their files
+	recordingDir  string          // where recordings are written
+	audience      map[string]bool // identities who may watch this recording back
+
+	tabs   []*Tab
+	tabSeq int             // tabs opened so far, for default names
+	shells map[string]*Tab // classroom: clientID -> private shell
+
+	secretHash []byte
+	invites    map[string]time.Time // invite token -> expiry
+
+	driverID        string // client holding the keyboard, "" when free
+	pendingDriverID string // client waiting for the driver to answer a request
+	lastInput       time.Time
+
+	seats    map[string]seat // identity -> seat held for a dropped participant
+	graceGen int             // bumped each time the room empties; guarded by the manager's mu
+
+	bans   []string        // identities, or "user:<name>", kept out of the room
+	muted  map[string]bool // clientID -> terminal input blocked by the host
+	kicked map[string]bool // clientIDs removed by the host; no seat on disconnect
+
+	removals map[string]RoomEvent // clientID -> why it was evicted, until its session asks
+
+	knocks   []*Knock        // people waiting for the host to let them in
+	approved map[string]bool // identities the host has let in
+
+	lastActivity time.Time
+	expiryWarned time.Time // deadline participants were last warned about
+	expiryReason string
+
+	eventLog []logEntry
+	lastSeq  uint64
+
+	store     RoomStore
+	persistMu sync.Mutex // held across snapshot and save, so saves land in order; taken before mu
 }
 
 func (r *Room) AddClient(client *Client) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
 
 	for i, c := range r.Connections {
 		if c.ID == client.ID {
-			if c.Events != nil {
-				close(c.Events)
-			}
+			close(c.notify)
 			r.Connections = remove(r.Connections, i)
 			break
 		}
 	}
 
+	// nobody is left to moderate - a room reloaded after a restart, or an
+	// interview whose host left the candidate behind for good - so whoever
+	// comes in next takes over
+	adopt := client.Role != RoleHost && !r.hostExpectedLocked() &&
+		(r.interview == nil || client.ID != r.interview.candidateID)
+	if adopt {
+		client.Role = RoleHost
+	}
+	r.assignModeRoleLocked(client)
+	delete(r.kicked, client.ID) // came back in after a kick
+	client.Name = r.uniqueNameLocked(client.Username)
+	client.Color = r.freeColorLocked()
+
+	// new clients start reading from now; state before this is in the room itself
+	client.notify = make(chan struct{}, 1)
+	client.cursor = r.lastSeq
+	client.joinedAt = time.Now()
 	r.Connections = append(r.Connections, client)
+	r.admitToRecordingLocked(client.Identity)
 
-	for _, c := range r.Connections {
-		if c.ID != client.ID && c.Events != nil {
-			select {
-			case c.Events <- RoomEvent{Type: "join", Username: client.Username}:
-			default:
-			}
-		}
+	r.broadcastLocked(JoinEvent{ClientID: client.ID, Username: client.Name, Role: client.Role}, client.ID)
+	// the candidate starts with the keyboard
+	if r.interview != nil && client.ID == r.interview.candidateID {
+		r.setDriverLocked(client.ID)
+	}
+	r.joinMobLocked(client)
+	if adopt {
+		r.promoteLocked(client, r.Host)
+	} else if client.IsHost() {
+		// back from a dropped connection; people may have knocked meanwhile
+		r.forwardKnocksLocked(client.ID)
+	}
+	r.mu.Unlock()
+
+	if adopt {
+		r.persist()
 	}
 }
 
 func (r *Room) RemoveClient(clientID string) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	c := r.removeClientLocked(clientID, false)
+	succeeded := c != nil && c.IsHost() && r.succeedHostLocked(c.Name)
+	// an empty room is about to close, which turns knocks away instead, and
+	// a host whose seat is held can still answer them
+	if c != nil && c.IsHost() && !succeeded && len(r.Connections) > 0 && !r.hostSeatHeldLocked() {
+		r.openDoorLocked()
+	}
+	r.mu.Unlock()
 
-	var removedUsername string
-	for i, c := range r.Connections {
-		if c.ID == clientID {
-			removedUsername = c.Username
-			if c.Events != nil {
-				close(c.Events)
-			}
-			r.Connections = remove(r.Connections, i)
-			break
-		}
+	if succeeded {
+		r.persist()
 	}
+}
 
-	if removedUsername != "" {
-		for _, c := range r.Connections {
-			if c.Events != nil {
-				select {
-				case c.Events <- RoomEvent{Type: "leave", Username: removedUsername}:
-				default:
-				}
-			}
-		}
+// removeClientLocked takes clientID out of the room, closing its notify
+// channel, and returns it (nil if it wasn't here). removed marks the
+// LeaveEvent as already announced by whatever removed them. Host
+// succession is up to the caller; callers must hold r.mu
+func (r *Room) removeClientLocked(clientID string, removed bool) *Client {
+	i := slices.IndexFunc(r.Connections, func(c *Client) bool { return c.ID == clientID })
+	if i < 0 {
+		return nil
+	}
+	c := r.Connections[i]
+	close(c.notify)
+	r.Connections = remove(r.Connections, i)
+
+	r.broadcastLocked(LeaveEvent{ClientID: clientID, Username: c.Name, Removed: removed}, "")
+	if r.pendingDriverID == clientID {
+		r.pendingDriverID = ""
+	}
+	if r.mob != nil {
+		r.leaveMobLocked(clientID)
+	} else if r.driverID == clientID {
+		next := r.pendingDriverID
+		r.pendingDriverID = ""
+		r.setDriverLocked(next)
+	}
+	return c
+}
+
+// evictLocked removes clientID from the room without waiting for its
+// session to notice. ev says why; the session picks it up from Removal
+// once its notify channel closes. Callers must hold r.mu
+func (r *Room) evictLocked(clientID string, ev RoomEvent) {
+	if r.removals == nil {
+		r.removals = make(map[string]RoomEvent)
+	}
+	r.removals[clientID] = ev
+	r.removeClientLocked(clientID, true)
+	r.closeShellLocked(clientID)
+}
+
+// Removal returns, once, the event that removed clientID from the room
+// server-side: a kick, a ban or the room closing.
+func (r *Room) Removal(clientID string) (RoomEvent, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ev, ok := r.removals[clientID]
+	delete(r.removals, clientID)
+	return ev, ok
+}
+
+// evictAll broadcasts ev and removes everyone, for a room being shut down
+// under its participants.
+func (r *Room) evictAll(ev RoomEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.broadcastLocked(ev, "")
+	for len(r.Connections) > 0 {
+		r.evictLocked(r.Connections[0].ID, ev)
 	}
 }
 
 func (r *Room) BroadcastEvent(event RoomEvent, excludeClientID string) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.broadcastLocked(event, excludeClientID)
+}
 
+// broadcastLocked sends event to every client but excludeClientID; callers must hold r.mu
+func (r *Room) broadcastLocked(event RoomEvent, excludeClientID string) {
+	r.appendLocked(logEntry{event: event, exclude: excludeClientID})
+}
+
+// sendLocked sends event to a single client; callers must hold r.mu
+func (r *Room) sendLocked(clientID string, event RoomEvent) {
+	r.appendLocked(logEntry{event: event, to: clientID})
+}
+
+// clientLocked finds a connected client by ID; callers must hold r.mu
+func (r *Room) clientLocked(clientID string) *Client {
 	for _, c := range r.Connections {
-		if c.ID != excludeClientID && c.Events != nil {
-			select {
-			case c.Events <- event:
-			default:
-			}
+		if c.ID == clientID {
+			return c
 		}
 	}
+	return nil
 }
 
 // https://stackoverflow.com/questions/37334119/how-to-delete-an-element-from-a-slice-in-golang
@@ -125,10 +263,13 @@ func (r *Room) ClientCount() int {
 	return len(r.Connections)
 }
 
-func (r *Room) SetAIMessages(msgs []AIMessage) {
+// SetAIMessages replaces the AI history and persists it so it survives restarts.
+func (r *Room) SetAIMessages(msgs []AIMessage) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
 	r.AIMessages = msgs
+	r.lastActivity = time.Now()
+	r.mu.Unlock()
+	return r.persist()
 }
 
 func (r *Room) GetAIMessages() []AIMessage {
@@ -138,3 +279,50 @@ func (r *Room) GetAIMessages() []AIMessage {
 	copy(result, r.AIMessages)
 	return result
 }
+
+// record snapshots the persistent parts of the room
+func (r *Room) record() RoomRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	msgs := make([]AIMessage, len(r.AIMessages))
+	copy(msgs, r.AIMessages)
+	chat := make([]ChatMessage, len(r.Chat))
+	copy(chat, r.Chat)
+	var problem string
+	if r.interview != nil {
+		problem = r.interview.problem
+	}
+	var rotation time.Duration
+	if r.mob != nil {
+		rotation = r.mob.rotation
+	}
+	return RoomRecord{
+		ID:          r.ID,
+		UUID:        r.UUID,
+		Description: r.Description,
+		Host:        r.Host,
+		CreatedAt:   r.CreatedAt,
+		Template:    r.Template,
+		Mode:        r.Mode,
+		Public:      r.Public,
+		Tags:        r.Tags,
+		Approval:    r.Approval,
+		Problem:     problem,
+		Rotation:    rotation,
+		AIMessages:  msgs,
+		Chat:        chat,
+		SecretHash:  r.secretHash,
+		Bans:        slices.Clone(r.bans),
+	}
+}
+
+// persist saves the room to its store. Concurrent calls take turns, so an
+// older snapshot can't overwrite a newer one; callers mustn't hold r.mu.
+func (r *Room) persist() error {
+	if r.store == nil {
+		return nil
+	}
+	r.persistMu.Lock()
+	defer r.persistMu.Unlock()
+	return r.store.Save(r.record())
+}
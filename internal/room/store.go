@@ -0,0 +1,141 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoomRecord is the persisted form of a room - everything needed to bring
+// it back after a restart except the live terminal and connections.
+type RoomRecord struct {
+	ID          string        `json:"id"`
+	UUID        string        `json:"uuid,omitempty"`
+	Description string        `json:"description"`
+	Host        string        `json:"host"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Template    string        `json:"template,omitempty"`
+	Mode        Mode          `json:"mode,omitempty"`
+	Public      bool          `json:"public,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Approval    bool          `json:"approval,omitempty"`
+	Problem     string        `json:"problem,omitempty"`
+	Rotation    time.Duration `json:"rotation,omitempty"`
+	AIMessages  []AIMessage   `json:"ai_messages"`
+	Chat        []ChatMessage `json:"chat,omitempty"`
+	SecretHash  []byte        `json:"secret_hash,omitempty"`
+	Bans        []string      `json:"bans,omitempty"`
+}
+
+// RoomStore persists room state so rooms survive server restarts
+type RoomStore interface {
+	Save(rec RoomRecord) error
+	Delete(roomID string) error
+	LoadAll() ([]RoomRecord, error)
+	// Has reports whether the store still holds a record under roomID,
+	// so its code isn't handed to a new room.
+	Has(roomID string) bool
+}
+
+// memoryStore is the default store: nothing outlives the process
+type memoryStore struct{}
+
+// NewMemoryStore returns a store that keeps nothing across restarts.
+func NewMemoryStore() RoomStore {
+	return memoryStore{}
+}
+
+func (memoryStore) Save(RoomRecord) error          { return nil }
+func (memoryStore) Delete(string) error            { return nil }
+func (memoryStore) LoadAll() ([]RoomRecord, error) { return nil, nil }
+func (memoryStore) Has(string) bool                { return false }
+
+// FileStore keeps one JSON file per room inside a directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates the directory if needed and returns a store backed by it.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create store dir: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(roomID string) string {
+	return filepath.Join(s.dir, roomID+".json")
+}
+
+// Save writes the record to a temp file and renames it over the old one,
+// so a crash mid-write never leaves a truncated room behind.
+func (s *FileStore) Save(rec RoomRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal room: %w", err)
+	}
+
+	tmp := s.path(rec.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write room: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(rec.ID)); err != nil {
+		return fmt.Errorf("rename room: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Delete(roomID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(roomID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete room: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Has(roomID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := os.Stat(s.path(roomID))
+	return err == nil
+}
+
+func (s *FileStore) LoadAll() ([]RoomRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read store dir: %w", err)
+	}
+
+	var records []RoomRecord
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read room %s: %w", e.Name(), err)
+		}
+		var rec RoomRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("decode room %s: %w", e.Name(), err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
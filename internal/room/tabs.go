@@ -0,0 +1,192 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jaypopat/duet/internal/terminal"
+)
+
+var (
+	ErrTabNotFound = errors.New("terminal tab not found")
+	ErrLastTab     = errors.New("can't close the last terminal")
+)
+
+// Tab is one named shell in a room. Rooms keep their tabs in order.
+type Tab struct {
+	ID       string
+	Name     string
+	Terminal *terminal.Terminal
+}
+
+// Tabs returns the room's terminals in display order.
+func (r *Room) Tabs() []Tab {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tabs := make([]Tab, len(r.tabs))
+	for i, t := range r.tabs {
+		tabs[i] = *t
+	}
+	return tabs
+}
+
+// Tab returns the tab with the given ID.
+func (r *Room) Tab(tabID string) (Tab, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if t := r.tabLocked(tabID); t != nil {
+		return *t, nil
+	}
+	return Tab{}, ErrTabNotFound
+}
+
+// EnsureTab returns the first tab, starting one at the given size if the
+// room has no terminals yet.
+func (r *Room) EnsureTab(width, height int) (Tab, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.tabs) > 0 {
+		return *r.tabs[0], nil
+	}
+	t, err := r.openTabLocked("", width, height)
+	if err != nil {
+		return Tab{}, err
+	}
+	return *t, nil
+}
+
+// OpenTab starts a new shell at the end of the tab strip. An empty name
+// picks one ("shell 2", "shell 3", ...).
+func (r *Room) OpenTab(clientID, name string, width, height int) (Tab, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.canEditTabsLocked(clientID); err != nil {
+		return Tab{}, err
+	}
+	t, err := r.openTabLocked(name, width, height)
+	if err != nil {
+		return Tab{}, err
+	}
+	r.broadcastLocked(TabEvent{Action: TabOpened, TabID: t.ID, Name: t.Name, ClientID: clientID}, "")
+	return *t, nil
+}
+
+// RenameTab changes a tab's display name.
+func (r *Room) RenameTab(clientID, tabID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.canEditTabsLocked(clientID); err != nil {
+		return err
+	}
+	t := r.tabLocked(tabID)
+	if t == nil {
+		return ErrTabNotFound
+	}
+	t.Name = name
+	r.broadcastLocked(TabEvent{Action: TabRenamed, TabID: t.ID, Name: t.Name, ClientID: clientID}, "")
+	return nil
+}
+
+// CloseTab kills a tab's shell. Clients viewing it should move elsewhere
+// when they see the TabEvent.
+func (r *Room) CloseTab(clientID, tabID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.canEditTabsLocked(clientID); err != nil {
+		return err
+	}
+	for i, t := range r.tabs {
+		if t.ID != tabID {
+			continue
+		}
+		if len(r.tabs) == 1 {
+			return ErrLastTab
+		}
+		r.tabs = append(r.tabs[:i], r.tabs[i+1:]...)
+		t.Terminal.Close()
+		r.broadcastLocked(TabEvent{Action: TabClosed, TabID: t.ID, Name: t.Name, ClientID: clientID}, "")
+		return nil
+	}
+	return ErrTabNotFound
+}
+
+// SetViewing records which tab a client is looking at and tells the others.
+func (r *Room) SetViewing(clientID, tabID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.clientLocked(clientID)
+	if c == nil || c.Viewing == tabID {
+		return
+	}
+	c.Viewing = tabID
+	r.broadcastLocked(ViewingEvent{ClientID: clientID, Username: c.Name, TabID: tabID}, clientID)
+}
+
+// closeTabs kills every shell in the room
+func (r *Room) closeTabs() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tabs {
+		t.Terminal.Close()
+	}
+	r.tabs = nil
+	for _, t := range r.shells {
+		t.Terminal.Close()
+	}
+	r.shells = nil
+}
+
+// openTabLocked starts a shell and appends it; callers must hold r.mu
+func (r *Room) openTabLocked(name string, width, height int) (*Tab, error) {
+	r.tabSeq++
+	if name == "" {
+		name = "shell"
+		if r.tabSeq > 1 {
+			name = fmt.Sprintf("shell %d", r.tabSeq)
+		}
+	}
+
+	term := terminal.New(width, height, r.template.terminalOptions(r.tabSeq == 1))
+	if err := term.Start(); err != nil {
+		return nil, err
+	}
+
+	t := &Tab{ID: uuid.New().String(), Name: name, Terminal: term}
+	r.tabs = append(r.tabs, t)
+	if r.recording {
+		// the tab works either way; it just won't be in the recording
+		r.recordTabLocked(t)
+	}
+	return t, nil
+}
+
+// tabLocked finds a tab by ID; callers must hold r.mu
+func (r *Room) tabLocked(tabID string) *Tab {
+	for _, t := range r.tabs {
+		if t.ID == tabID {
+			return t
+		}
+	}
+	return nil
+}
+
+// canEditTabsLocked checks clientID may open, rename or close the shared
+// tabs: the same people who may type in them; callers must hold r.mu
+func (r *Room) canEditTabsLocked(clientID string) error {
+	if r.muted[clientID] {
+		return ErrMuted
+	}
+	if c := r.clientLocked(clientID); c == nil || !c.Role.CanWrite() {
+		return ErrReadOnly
+	}
+	return nil
+}
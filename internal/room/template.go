@@ -0,0 +1,88 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jaypopat/duet/internal/terminal"
+)
+
+var ErrTemplateNotFound = errors.New("room template not found")
+
+// Template is a reusable room setup - what the shell runs, where, and what
+// it types first - so rooms can boot straight into a prepared exercise.
+type Template struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"` // default room description
+	Shell       string            `json:"shell"`       // command line, defaults to $SHELL
+	Dir         string            `json:"dir"`         // working directory
+	Env         map[string]string `json:"env"`
+	Init        string            `json:"init"`    // typed into the first terminal on start
+	Problem     string            `json:"problem"` // markdown pinned in interview rooms
+}
+
+// LoadTemplates reads a JSON array of templates from path.
+func LoadTemplates(path string) ([]Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read templates: %w", err)
+	}
+
+	var templates []Template
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("decode templates: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range templates {
+		if t.Name == "" {
+			return nil, errors.New("template without a name")
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate template %q", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return templates, nil
+}
+
+// terminalOptions turns the template into shell settings. Only the first
+// terminal in a room runs the init script.
+func (t *Template) terminalOptions(first bool) terminal.Options {
+	if t == nil {
+		return terminal.Options{}
+	}
+
+	env := make([]string, 0, len(t.Env))
+	for k, v := range t.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+
+	opts := terminal.Options{Shell: t.Shell, Dir: t.Dir, Env: env}
+	if first {
+		opts.Init = t.Init
+	}
+	return opts
+}
+
+// Templates returns the templates rooms can be created from.
+func (m *Manager) Templates() []Template {
+	return m.cfg.Templates
+}
+
+// template finds a configured template by name; "" means none
+func (m *Manager) template(name string) (*Template, error) {
+	if name == "" {
+		return nil, nil
+	}
+	for i := range m.cfg.Templates {
+		if m.cfg.Templates[i].Name == name {
+			return &m.cfg.Templates[i], nil
+		}
+	}
+	return nil, ErrTemplateNotFound
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -17,6 +18,7 @@ import (
 	"github.com/jaypopat/duet/internal/room"
 	"github.com/jaypopat/duet/internal/ui"
 	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 type Server struct {
@@ -27,23 +29,48 @@ type Server struct {
 	logger      *log.Logger
 }
 
-func New(addr, hostKeyPath, workerURL string) *Server {
+// modelKey stores a session's UI model in its context so it can be told
+// when the session ends
+type modelKey struct{}
+
+// New creates a server. When dataDir is set rooms are persisted there and
+// reloaded on startup; otherwise they only live in memory. roomCfg controls
+// room lifetimes such as the reconnect grace period.
+func New(addr, hostKeyPath, workerURL, dataDir string, roomCfg room.Config) (*Server, error) {
+	store := room.NewMemoryStore()
+	if dataDir != "" {
+		fs, err := room.NewFileStore(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		store = fs
+	}
+
+	roomManager, err := room.NewManager(store, roomCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rooms: %w", err)
+	}
+
 	return &Server{
 		addr:        addr,
 		hostKeyPath: hostKeyPath,
 		workerURL:   workerURL,
-		roomManager: room.NewManager(),
+		roomManager: roomManager,
 		logger: log.NewWithOptions(os.Stderr, log.Options{
 			Prefix: "duet",
 		}),
-	}
+	}, nil
 }
 
 func (s *Server) Start() error {
 	srv, err := wish.NewServer(
 		wish.WithAddress(s.addr),
 		wish.WithHostKeyPath(s.hostKeyPath),
+		// accept everyone; keys are only asked for so we can fingerprint sessions
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
 		wish.WithMiddleware(
+			s.disconnectMiddleware,
 			bubbletea.Middleware(s.teaHandler),
 			logging.Middleware(),
 		),
@@ -55,6 +82,8 @@ func (s *Server) Start() error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	s.logger.Info("Rooms loaded", "count", s.roomManager.RoomCount())
+
 	go func() {
 		s.logger.Info("Starting SSH server", "address", s.addr)
 		if err := srv.ListenAndServe(); err != nil {
@@ -65,6 +94,9 @@ func (s *Server) Start() error {
 	<-ctx.Done()
 
 	s.logger.Info("Shutting down...")
+	if err := s.roomManager.Close(); err != nil {
+		s.logger.Error("Failed to persist rooms", "error", err)
+	}
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -88,7 +120,32 @@ func (s *Server) teaHandler(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
 		"profile", renderer.ColorProfile(),
 		"hasDark", renderer.HasDarkBackground(),
 	)
-	return ui.New(renderer, s.roomManager, s.workerURL, username), []tea.ProgramOption{
+	var fingerprint string
+	if pk := sess.PublicKey(); pk != nil {
+		fingerprint = gossh.FingerprintSHA256(pk)
+	}
+
+	// failed joins are limited per address; keys and usernames cost nothing
+	addr := sess.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	model := ui.New(renderer, s.roomManager, s.workerURL, username, fingerprint, addr)
+	sess.Context().SetValue(modelKey{}, model)
+
+	return model, []tea.ProgramOption{
 		tea.WithAltScreen(),
 	}
 }
+
+// disconnectMiddleware runs after the bubbletea program has exited and lets
+// the session's model release its room seat.
+func (s *Server) disconnectMiddleware(next ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		if model, ok := sess.Context().Value(modelKey{}).(*ui.Model); ok {
+			model.Disconnect()
+		}
+		next(sess)
+	}
+}
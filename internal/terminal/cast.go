@@ -0,0 +1,223 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrRecording = errors.New("terminal is already being recorded")
+
+// castRecorder writes a session as asciicast v2: a JSON header line, then
+// one [seconds, code, data] line per output ("o") or resize ("r") event.
+// See https://docs.asciinema.org/manual/asciicast/v2/
+type castRecorder struct {
+	w       io.WriteCloser
+	start   time.Time
+	partial []byte // a UTF-8 sequence split across PTY reads
+}
+
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env"`
+}
+
+// StartRecording writes everything the terminal shows from now on to w as
+// an asciicast v2 recording. w is closed by StopRecording, or when the
+// terminal closes.
+func (t *Terminal) StartRecording(w io.WriteCloser, title string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rec != nil {
+		return ErrRecording
+	}
+	rec := &castRecorder{w: w, start: time.Now()}
+	header, err := json.Marshal(castHeader{
+		Version:   2,
+		Width:     t.width,
+		Height:    t.height,
+		Timestamp: rec.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(header, '\n')); err != nil {
+		return fmt.Errorf("write cast header: %w", err)
+	}
+	t.rec = rec
+	return nil
+}
+
+// StopRecording finishes the recording, if there is one, and closes its
+// writer.
+func (t *Terminal) StopRecording() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stopRecordingLocked()
+}
+
+// Recording reports whether the terminal is being recorded.
+func (t *Terminal) Recording() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rec != nil
+}
+
+// stopRecordingLocked closes the recorder; callers must hold t.mu
+func (t *Terminal) stopRecordingLocked() error {
+	if t.rec == nil {
+		return nil
+	}
+	err := t.rec.w.Close()
+	t.rec = nil
+	return err
+}
+
+// recordLocked adds an event to the recording, giving up on it if the
+// writer fails; callers must hold t.mu
+func (t *Terminal) recordLocked(code, data string) {
+	if t.rec == nil {
+		return
+	}
+	if err := t.rec.event(code, data); err != nil {
+		t.stopRecordingLocked()
+	}
+}
+
+// output records PTY output, holding back a trailing partial UTF-8
+// sequence until the rest of it arrives so the JSON stays valid
+func (r *castRecorder) output(p []byte) string {
+	p = append(r.partial, p...)
+	r.partial = nil
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				r.partial = append([]byte(nil), p[i:]...)
+				p = p[:i]
+			}
+			break
+		}
+	}
+	return string(p)
+}
+
+func (r *castRecorder) event(code, data string) error {
+	if data == "" {
+		return nil
+	}
+	elapsed := math.Round(time.Since(r.start).Seconds()*1e6) / 1e6
+	line, err := json.Marshal([]any{elapsed, code, data})
+	if err != nil {
+		return err
+	}
+	_, err = r.w.Write(append(line, '\n'))
+	return err
+}
+
+// Cast is a recording loaded back in for playback.
+type Cast struct {
+	Width     int
+	Height    int
+	Title     string
+	Timestamp time.Time
+	Events    []CastEvent
+}
+
+// CastEvent is one thing that happened in a recording.
+type CastEvent struct {
+	Time time.Duration // since the recording started
+	Code string        // "o" for output, "r" for a resize to "WxH"
+	Data string
+}
+
+// ReadCast parses an asciicast v2 recording. Event types other than output
+// and resize are skipped.
+func ReadCast(r io.Reader) (*Cast, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("empty recording")
+	}
+	var h castHeader
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, fmt.Errorf("read cast header: %w", err)
+	}
+	if h.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d", h.Version)
+	}
+	c := &Cast{Width: h.Width, Height: h.Height, Title: h.Title, Timestamp: time.Unix(h.Timestamp, 0)}
+
+	for line := 2; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var raw []json.RawMessage
+		var ev CastEvent
+		var secs float64
+		if err := json.Unmarshal(sc.Bytes(), &raw); err != nil || len(raw) != 3 ||
+			json.Unmarshal(raw[0], &secs) != nil ||
+			json.Unmarshal(raw[1], &ev.Code) != nil ||
+			json.Unmarshal(raw[2], &ev.Data) != nil {
+			return nil, fmt.Errorf("read cast line %d: malformed event", line)
+		}
+		if ev.Code != "o" && ev.Code != "r" {
+			continue
+		}
+		ev.Time = time.Duration(secs * float64(time.Second))
+		c.Events = append(c.Events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Duration is how long the recording runs.
+func (c *Cast) Duration() time.Duration {
+	if len(c.Events) == 0 {
+		return 0
+	}
+	return c.Events[len(c.Events)-1].Time
+}
+
+// CompressIdle returns a copy of the recording with every pause longer
+// than limit cut down to limit, like asciinema's --idle-time-limit.
+func (c *Cast) CompressIdle(limit time.Duration) *Cast {
+	out := *c
+	out.Events = make([]CastEvent, len(c.Events))
+	var prev, shift time.Duration
+	for i, ev := range c.Events {
+		if gap := ev.Time - prev; gap > limit {
+			shift += gap - limit
+		}
+		prev = ev.Time
+		ev.Time -= shift
+		out.Events[i] = ev
+	}
+	return &out
+}
+
+// ParseSize reads a resize event's "WxH".
+func ParseSize(s string) (width, height int, ok bool) {
+	if _, err := fmt.Sscanf(s, "%dx%d", &width, &height); err != nil {
+		return 0, 0, false
+	}
+	return width, height, width > 0 && height > 0
+}
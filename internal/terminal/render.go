@@ -0,0 +1,173 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hinshun/vt10x"
+	"github.com/muesli/termenv"
+)
+
+// Glyph.Mode bits; vt10x doesn't export them. It drops faint and
+// strikethrough altogether, so those can't be shown.
+const (
+	attrReverse = 1 << iota
+	attrUnderline
+	attrBold
+	attrGfx
+	attrItalic
+	attrBlink
+
+	attrShown = attrReverse | attrUnderline | attrBold | attrItalic | attrBlink
+)
+
+// pen is what a run of cells is drawn with
+type pen struct {
+	fg, bg vt10x.Color
+	mode   int16
+}
+
+var plainPen = pen{fg: vt10x.DefaultFG, bg: vt10x.DefaultBG}
+
+// rowLocked copies row y of the screen; callers must hold t.mu
+func (t *Terminal) rowLocked(y, cols int) []vt10x.Glyph {
+	row := make([]vt10x.Glyph, cols)
+	for x := range row {
+		row[x] = t.vt.Cell(x, y)
+	}
+	return row
+}
+
+// rowCache is the rendered screen for one color profile, row by row
+type rowCache struct {
+	rows    []string
+	cursors []int  // cursorX each row was drawn with
+	valid   []bool // false once the row's cells change
+}
+
+// rowCacheLocked returns profile's row cache sized for the screen;
+// callers must hold t.mu
+func (t *Terminal) rowCacheLocked(profile termenv.Profile, rows int) *rowCache {
+	c := t.rowCaches[profile]
+	if c == nil || len(c.rows) != rows {
+		c = &rowCache{rows: make([]string, rows), cursors: make([]int, rows), valid: make([]bool, rows)}
+		t.rowCaches[profile] = c
+	}
+	return c
+}
+
+// damageLocked compares the screen with the cells from the last render and
+// invalidates the cached rows that changed; callers must hold t.mu
+func (t *Terminal) damageLocked() {
+	cols, rows := t.vt.Size()
+	if len(t.cells) != rows || len(t.cells) > 0 && len(t.cells[0]) != cols {
+		t.cells = make([][]vt10x.Glyph, rows)
+		for y := range t.cells {
+			t.cells[y] = t.rowLocked(y, cols)
+		}
+		clear(t.rowCaches)
+		return
+	}
+
+	for y, row := range t.cells {
+		changed := false
+		for x := range row {
+			if cell := t.vt.Cell(x, y); cell != row[x] {
+				row[x] = cell
+				changed = true
+			}
+		}
+		if changed {
+			for _, c := range t.rowCaches {
+				if y < len(c.valid) {
+					c.valid[y] = false
+				}
+			}
+		}
+	}
+}
+
+// renderRow writes a row of cells with SGR sequences for their colors and
+// attributes, starting a new sequence only where the pen changes. The
+// cursor, at cursorX (-1 for none), is drawn in reverse video.
+func renderRow(sb *strings.Builder, cells []vt10x.Glyph, cursorX int, profile termenv.Profile) {
+	prev := plainPen
+	for x, cell := range cells {
+		p := pen{fg: cell.FG, bg: cell.BG, mode: cell.Mode & attrShown}
+		if p.mode&attrReverse != 0 {
+			// vt10x swaps the colors itself; undo it and let the
+			// viewer's terminal reverse them, which also works for the
+			// default colors
+			p.fg, p.bg = p.bg, p.fg
+		}
+		if x == cursorX {
+			p.mode ^= attrReverse
+		}
+
+		if p != prev {
+			if prev != plainPen {
+				sb.WriteString("\x1b[0m")
+			}
+			if p != plainPen {
+				sb.WriteString(sgr(p, profile))
+			}
+			prev = p
+		}
+
+		char := cell.Char
+		if char == 0 {
+			char = ' '
+		}
+		sb.WriteRune(char)
+	}
+	if prev != plainPen {
+		sb.WriteString("\x1b[0m")
+	}
+}
+
+// sgr returns the escape sequence that draws with p
+func sgr(p pen, profile termenv.Profile) string {
+	var params []string
+	for _, a := range []struct {
+		bit   int16
+		param string
+	}{
+		{attrBold, termenv.BoldSeq},
+		{attrItalic, termenv.ItalicSeq},
+		{attrUnderline, termenv.UnderlineSeq},
+		{attrBlink, termenv.BlinkSeq},
+		{attrReverse, termenv.ReverseSeq},
+	} {
+		if p.mode&a.bit != 0 {
+			params = append(params, a.param)
+		}
+	}
+	if seq := colorSeq(p.fg, false, profile); seq != "" {
+		params = append(params, seq)
+	}
+	if seq := colorSeq(p.bg, true, profile); seq != "" {
+		params = append(params, seq)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return termenv.CSI + strings.Join(params, ";") + "m"
+}
+
+// colorSeq converts a vt10x color for the viewer's profile. vt10x packs
+// 24-bit colors as 0xRRGGBB above the 256-color palette; dark truecolor
+// values that land inside the palette can't be told apart from it.
+func colorSeq(c vt10x.Color, bg bool, profile termenv.Profile) string {
+	var tc termenv.Color
+	switch {
+	case c >= vt10x.DefaultFG:
+		return ""
+	case c < 16:
+		tc = termenv.ANSIColor(c)
+	case c < 256:
+		tc = termenv.ANSI256Color(c)
+	default:
+		tc = termenv.RGBColor(fmt.Sprintf("#%06x", uint32(c)))
+	}
+	return profile.Convert(tc).Sequence(bg)
+}
@@ -0,0 +1,25 @@
+package terminal
+
+import "github.com/hinshun/vt10x"
+
+// NewReplay returns a terminal with no shell behind it, for playing back a
+// recording through the same emulator and renderer as a live session.
+func NewReplay(width, height int) *Terminal {
+	t := New(width, height, Options{})
+	t.vt = vt10x.New(vt10x.WithSize(t.width, t.height))
+	t.history = newScrollback(t.opts.Scrollback)
+	return t
+}
+
+// Feed writes recorded output to the screen as if the shell had just
+// produced it.
+func (t *Terminal) Feed(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.vt == nil {
+		return
+	}
+	t.writeLocked(p)
+	t.dirty = true
+}
@@ -0,0 +1,332 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hinshun/vt10x"
+	"github.com/muesli/termenv"
+)
+
+// DefaultScrollback is how many lines a terminal keeps once they scroll
+// off the top of the screen, unless Options.Scrollback says otherwise.
+const DefaultScrollback = 1000
+
+// scrollback is a ring of lines that have scrolled off the screen, kept as
+// cells so each viewer can have them rendered for their own terminal
+type scrollback struct {
+	lines [][]vt10x.Glyph
+	start int    // index of the oldest line once the ring is full
+	total uint64 // lines ever pushed, so viewers can tell how far it moved
+}
+
+func newScrollback(size int) *scrollback {
+	if size < 1 {
+		size = DefaultScrollback
+	}
+	return &scrollback{lines: make([][]vt10x.Glyph, 0, size)}
+}
+
+func (s *scrollback) push(line []vt10x.Glyph) {
+	s.total++
+	if len(s.lines) < cap(s.lines) {
+		s.lines = append(s.lines, line)
+		return
+	}
+	s.lines[s.start] = line
+	s.start = (s.start + 1) % len(s.lines)
+}
+
+func (s *scrollback) len() int {
+	return len(s.lines)
+}
+
+// line returns the i'th kept line, oldest first
+func (s *scrollback) line(i int) []vt10x.Glyph {
+	return s.lines[(s.start+i)%len(s.lines)]
+}
+
+// Scrollback reports how many lines of history the terminal holds, and how
+// many have ever scrolled off, which keeps growing after the oldest are
+// dropped.
+func (t *Terminal) Scrollback() (kept int, total uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.history == nil {
+		return 0, 0
+	}
+	return t.history.len(), t.history.total
+}
+
+// RenderScrolled renders the screen as it looks scrolled back offset lines
+// into history. An offset of 0 is the live screen, the same as Render.
+func (t *Terminal) RenderScrolled(offset int, profile termenv.Profile) string {
+	if offset <= 0 {
+		return t.Render(profile)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.vt == nil || t.history == nil {
+		return ""
+	}
+	offset = min(offset, t.history.len())
+
+	cols, rows := t.vt.Size()
+	var sb strings.Builder
+	sb.Grow(cols * rows * 2)
+	for y := range rows {
+		// history lines first, then the top of the live screen
+		if i := t.history.len() - offset + y; i < t.history.len() {
+			renderRow(&sb, fitRow(t.history.line(i), cols), -1, profile)
+		} else {
+			renderRow(&sb, t.rowLocked(i-t.history.len(), cols), -1, profile)
+		}
+		if y < rows-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// fitRow cuts or pads a history line to the screen's current width; it
+// keeps the width it had when it scrolled off, which a resize may change
+func fitRow(line []vt10x.Glyph, cols int) []vt10x.Glyph {
+	if len(line) >= cols {
+		return line[:cols]
+	}
+	row := make([]vt10x.Glyph, cols)
+	copy(row, line)
+	for x := len(line); x < cols; x++ {
+		row[x] = vt10x.Glyph{Char: ' ', FG: vt10x.DefaultFG, BG: vt10x.DefaultBG}
+	}
+	return row
+}
+
+// outputState is where the scanner in writeLocked is in the escape
+// sequence grammar, mirroring vt10x's parser closely enough to spot the
+// sequences that scroll or change the scroll region
+type outputState int
+
+const (
+	outGround outputState = iota
+	outEsc                // after ESC
+	outEscArg             // ESC ( and friends take one more byte
+	outCSI                // ESC [, collecting parameters
+	outStr                // OSC, DCS and friends, up to BEL or ESC \
+	outStrEnd             // ESC inside a string
+)
+
+// scroller follows the emulator's scroll region, which vt10x doesn't
+// expose, so writeLocked knows which row a scroll pushes off the screen
+type scroller struct {
+	state  outputState
+	csi    []byte
+	top    int
+	bottom int // -1 for the last row
+}
+
+// resetRegion goes back to scrolling the whole screen, as vt10x does on a
+// reset or resize
+func (s *scroller) resetRegion() {
+	s.top, s.bottom = 0, -1
+}
+
+// region returns the scroll region's first and last rows
+func (s *scroller) region(rows int) (top, bottom int) {
+	if s.bottom < 0 || s.bottom >= rows {
+		return min(s.top, rows-1), rows - 1
+	}
+	return s.top, s.bottom
+}
+
+// csiArgs parses CSI parameters the way vt10x does: a leading '?' marks a
+// private sequence, and parsing stops at the first missing or bad number.
+func csiArgs(buf []byte) (args []int, priv bool) {
+	s := string(buf)
+	if strings.HasPrefix(s, "?") {
+		priv, s = true, s[1:]
+	}
+	for _, p := range strings.Split(s, ";") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		args = append(args, n)
+	}
+	return args, priv
+}
+
+func csiArg(args []int, i, def int) int {
+	if i < len(args) {
+		return args[i]
+	}
+	return def
+}
+
+// writeLocked feeds PTY output to the emulator, saving rows to history as
+// they scroll off the top of the screen. Output is split wherever it could
+// scroll - line feeds, ESC D/E, CSI S and text that may wrap on the bottom
+// row - so each row can be saved just before it goes. Only scrolls of a
+// region starting at the top row are kept, like xterm, and full-screen
+// programs on the alternate screen don't add to history.
+// Callers must hold t.mu.
+func (t *Terminal) writeLocked(p []byte) {
+	sc := &t.scroll
+	from := 0
+	flush := func(to int) {
+		if to > from {
+			t.vt.Write(p[from:to])
+		}
+		from = to
+	}
+
+	for i := 0; i < len(p); i++ {
+		b := p[i]
+		// vt10x acts on line feeds in the middle of escape sequences, but not strings
+		if (b == '\n' || b == '\v' || b == '\f') && sc.state != outStr {
+			flush(i)
+			t.scrollWriteLocked(p[i:i+1], 1, true)
+			from = i + 1
+			continue
+		}
+
+		// other control codes don't end a sequence either; ESC starts a new one
+		if (b < 0x20 || b == 0x7f) && sc.state != outStr {
+			if b == 0x1b {
+				sc.state = outEsc
+			}
+			continue
+		}
+
+		switch sc.state {
+		case outGround:
+			// a run of text, which can wrap and scroll at the bottom
+			flush(i)
+			i = t.writeTextLocked(p, i) - 1
+			from = i + 1
+		case outEsc:
+			sc.state = outGround
+			switch b {
+			case '[':
+				sc.state, sc.csi = outCSI, sc.csi[:0]
+			case ']', 'P', '_', '^', 'k':
+				sc.state = outStr
+			case '(', ')', '*', '+', '#':
+				sc.state = outEscArg
+			case 'D', 'E': // IND, NEL
+				flush(i)
+				t.scrollWriteLocked(p[i:i+1], 1, true)
+				from = i + 1
+			case 'c': // RIS
+				sc.resetRegion()
+			}
+		case outEscArg, outStrEnd:
+			sc.state = outGround
+		case outCSI:
+			sc.csi = append(sc.csi, b)
+			if (b < 0x40 || b > 0x7e) && len(sc.csi) < 256 {
+				break
+			}
+			sc.state = outGround
+			args, priv := csiArgs(sc.csi[:len(sc.csi)-1])
+			switch {
+			case b == 'S' && !priv: // SU
+				flush(i)
+				t.scrollWriteLocked(p[i:i+1], csiArg(args, 0, 1), false)
+				from = i + 1
+			case b == 'r' && !priv: // DECSTBM
+				_, rows := t.vt.Size()
+				top := min(max(csiArg(args, 0, 1)-1, 0), rows-1)
+				bottom := min(max(csiArg(args, 1, rows)-1, 0), rows-1)
+				if top > bottom {
+					top, bottom = bottom, top
+				}
+				sc.top, sc.bottom = top, bottom
+			}
+		case outStr:
+			switch b {
+			case 0x1b:
+				sc.state = outStrEnd
+			case 0x07:
+				sc.state = outGround
+			}
+		}
+	}
+	flush(len(p))
+}
+
+// writeTextLocked writes the run of text starting at p[i], a screen width
+// at most at a time so each piece wraps at most once, and returns where
+// the run ended. Callers must hold t.mu.
+func (t *Terminal) writeTextLocked(p []byte, i int) int {
+	cols, _ := t.vt.Size()
+	for i < len(p) && p[i] >= 0x20 && p[i] != 0x7f {
+		j, n := i, 0
+		for j < len(p) && p[j] >= 0x20 && p[j] != 0x7f {
+			// count runes, stopping before one that would go over the limit
+			if p[j]&0xc0 != 0x80 {
+				if n == max(cols-1, 1) {
+					break
+				}
+				n++
+			}
+			j++
+		}
+
+		before := t.vt.Cursor()
+		var saved [][]vt10x.Glyph
+		if before.X+n >= cols {
+			saved = t.topRowsLocked(before.Y, 1)
+		}
+		t.vt.Write(p[i:j])
+		// without wrapping the cursor ends up n along, or stuck at the edge
+		if after := t.vt.Cursor(); saved != nil && after.Y == before.Y && after.X != min(before.X+n, cols-1) {
+			t.pushLocked(saved)
+		}
+		i = j
+	}
+	return i
+}
+
+// scrollWriteLocked writes p, a single byte that may scroll the region up
+// by n lines: a line feed or IND/NEL only when the cursor is on the
+// region's bottom row (atBottom), CSI S always. Callers must hold t.mu.
+func (t *Terminal) scrollWriteLocked(p []byte, n int, atBottom bool) {
+	y := -1
+	if atBottom {
+		y = t.vt.Cursor().Y
+	}
+	saved := t.topRowsLocked(y, n)
+	t.vt.Write(p)
+	t.pushLocked(saved)
+}
+
+// topRowsLocked copies the n rows a scroll is about to push off the top of
+// the screen, or returns nil if it won't: the cursor isn't on the bottom
+// row of the scroll region (y < 0 skips that check), the region doesn't
+// start at the top, or the alternate screen is up. Callers must hold t.mu.
+func (t *Terminal) topRowsLocked(y, n int) [][]vt10x.Glyph {
+	if t.history == nil || t.vt.Mode()&vt10x.ModeAltScreen != 0 {
+		return nil
+	}
+	cols, rows := t.vt.Size()
+	top, bottom := t.scroll.region(rows)
+	if top != 0 || (y >= 0 && y != bottom) {
+		return nil
+	}
+	n = min(n, bottom+1)
+	saved := make([][]vt10x.Glyph, n)
+	for i := range saved {
+		saved[i] = t.rowLocked(i, cols)
+	}
+	return saved
+}
+
+func (t *Terminal) pushLocked(rows [][]vt10x.Glyph) {
+	for _, row := range rows {
+		t.history.push(row)
+	}
+}
@@ -0,0 +1,116 @@
+package terminal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// historyText returns the terminal's scrollback as trimmed lines, oldest first
+func historyText(t *Terminal) []string {
+	var lines []string
+	for i := range t.history.len() {
+		var sb strings.Builder
+		for _, g := range t.history.line(i) {
+			sb.WriteRune(g.Char)
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " \x00"))
+	}
+	return lines
+}
+
+func TestScrollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string // fed one at a time, to split sequences across writes
+		want   []string
+	}{
+		{
+			name:   "line feeds",
+			output: []string{"a\r\nb\r\nc\r\nd\r\ne"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "wrap on the bottom row",
+			output: []string{"x\r\ny\r\n0123456789abcdefghijK"},
+			want:   []string{"x", "y"},
+		},
+		{
+			name:   "region at the top",
+			output: []string{"a\r\nb\r\nc", "\x1b[1;2r\x1b[2;1H\n"},
+			want:   []string{"a"},
+		},
+		{
+			name:   "region below the top",
+			output: []string{"a\r\nb\r\nc", "\x1b[2;3r\x1b[3;1H\n\n"},
+			want:   nil,
+		},
+		{
+			name:   "region reset",
+			output: []string{"a\r\nb\r\nc", "\x1b[2;3r\x1b[r\x1b[3;1H\n"},
+			want:   []string{"a"},
+		},
+		{
+			name:   "scroll up",
+			output: []string{"a\r\nb\r\nc\x1b[2S"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "scroll up split across writes",
+			output: []string{"a\r\nb\r\nc\x1b[", "2", "S"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "index and next line",
+			output: []string{"a\r\nb\r\nc\x1bD\x1bE"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "alt screen",
+			output: []string{"\x1b[?1049ha\r\nb\r\nc\r\nd\r\ne\x1b[?1049l"},
+			want:   nil,
+		},
+		{
+			name:   "line feed inside an OSC title",
+			output: []string{"a\r\nb\r\nc\x1b]0;x\ny\x07"},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewReplay(10, 3)
+			for _, p := range tt.output {
+				term.Feed([]byte(p))
+			}
+			if got := historyText(term); !slices.Equal(got, tt.want) {
+				t.Errorf("history = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderScrolledAfterShrink(t *testing.T) {
+	term := NewReplay(10, 3)
+	term.Feed([]byte("0123456789\r\nb\r\nc\r\nd"))
+	term.Resize(4, 3)
+
+	for _, line := range strings.Split(term.RenderScrolled(1, 0), "\n") {
+		if n := len([]rune(stripANSI(line))); n > 4 {
+			t.Errorf("row %q is %d cells wide, want at most 4", line, n)
+		}
+	}
+}
+
+// stripANSI drops escape sequences from a rendered row
+func stripANSI(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			for i++; i < len(s) && (s[i] < 0x40 || s[i] > 0x7e || s[i] == '['); i++ {
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -6,17 +6,35 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/creack/pty"
 	"github.com/hinshun/vt10x"
+	"github.com/muesli/termenv"
 )
 
+// Options controls how the shell is started. The zero value runs $SHELL
+// in the server's working directory.
+type Options struct {
+	Shell string   // command line to run, split on whitespace
+	Dir   string   // working directory
+	Env   []string // extra KEY=VALUE pairs on top of the server's environment
+	Init  string   // typed into the shell once it starts
+
+	Scrollback int // lines of history to keep; 0 means DefaultScrollback
+}
+
 // Terminal wraps a PTY with vt10x terminal emulation
 type Terminal struct {
-	vt   vt10x.Terminal
-	ptmx *os.File
-	cmd  *exec.Cmd
-	mu   sync.Mutex
+	opts Options
+
+	vt      vt10x.Terminal
+	history *scrollback   // lines that have scrolled off the top
+	scroll  scroller      // tracks the scroll region for history
+	rec     *castRecorder // set while the session is being recorded
+	ptmx    *os.File
+	cmd     *exec.Cmd
+	mu      sync.Mutex
 
 	width  int
 	height int
@@ -27,11 +45,20 @@ type Terminal struct {
 	closed      bool
 
 	// Render optimization
-	lastRender string // cached render output
-	dirty      bool   // needs re-render
+	renders   map[termenv.Profile]string    // cached output per color profile
+	rowCaches map[termenv.Profile]*rowCache // rendered rows per color profile
+	cells     [][]vt10x.Glyph               // screen as of the last render, to find changed rows
+	dirty     bool                          // screen changed since the cache was filled
+
+	// Frame-rate cap for subscriber notifications
+	frameMu      sync.Mutex
+	lastFrame    time.Time
+	framePending bool // a notification is scheduled for the end of this frame
+
+	lastOutput time.Time // last time the PTY produced output
 }
 
-func New(width, height int) *Terminal {
+func New(width, height int, opts Options) *Terminal {
 	if width < 1 {
 		width = 80
 	}
@@ -40,27 +67,74 @@ func New(width, height int) *Terminal {
 	}
 
 	return &Terminal{
+		opts:        opts,
 		width:       width,
 		height:      height,
+		scroll:      scroller{bottom: -1},
 		subscribers: make(map[chan struct{}]struct{}),
+		renders:     make(map[termenv.Profile]string),
+		rowCaches:   make(map[termenv.Profile]*rowCache),
 	}
 }
 
 // Subscribe creates a new channel for receiving update notifications.
-// we call Unsubscribe when done to avoid leaks.
+// we call Unsubscribe when done to avoid leaks. Subscribing to a closed
+// terminal, which a tab can be by the time someone switches to it, gets
+// an already closed channel.
 func (t *Terminal) Subscribe() chan struct{} {
 	ch := make(chan struct{}, 1)
 	t.subMu.Lock()
+	defer t.subMu.Unlock()
+
+	if t.subscribers == nil {
+		close(ch)
+		return ch
+	}
 	t.subscribers[ch] = struct{}{}
-	t.subMu.Unlock()
 	return ch
 }
 
 // Unsubscribe removes a channel from the subscriber list and closes it.
 func (t *Terminal) Unsubscribe(ch chan struct{}) {
 	t.subMu.Lock()
-	delete(t.subscribers, ch)
-	t.subMu.Unlock()
+	defer t.subMu.Unlock()
+
+	// Close may already have closed it
+	if _, ok := t.subscribers[ch]; ok {
+		delete(t.subscribers, ch)
+		close(ch)
+	}
+}
+
+// maxFrameRate caps how often subscribers hear about new output. A chatty
+// build can produce thousands of reads a second; viewers only need to
+// redraw at a rate people can see.
+const maxFrameRate = 30
+
+// notify broadcasts an update, or if one went out less than a frame ago,
+// schedules one for the end of the frame so a burst of output comes out
+// as a single redraw
+func (t *Terminal) notify() {
+	t.frameMu.Lock()
+	defer t.frameMu.Unlock()
+
+	if t.framePending {
+		return
+	}
+	wait := time.Until(t.lastFrame.Add(time.Second / maxFrameRate))
+	if wait <= 0 {
+		t.lastFrame = time.Now()
+		t.broadcast()
+		return
+	}
+	t.framePending = true
+	time.AfterFunc(wait, func() {
+		t.frameMu.Lock()
+		t.framePending = false
+		t.lastFrame = time.Now()
+		t.frameMu.Unlock()
+		t.broadcast()
+	})
 }
 
 // broadcast sends an update signal to all subscribers
@@ -81,16 +155,23 @@ func (t *Terminal) Start() error {
 	defer t.mu.Unlock()
 
 	t.vt = vt10x.New(vt10x.WithSize(t.width, t.height))
+	t.history = newScrollback(t.opts.Scrollback)
 
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/sh"
+	args := strings.Fields(t.opts.Shell)
+	if len(args) == 0 {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		args = []string{shell}
 	}
 
-	t.cmd = exec.Command(shell)
+	t.cmd = exec.Command(args[0], args[1:]...)
+	t.cmd.Dir = t.opts.Dir
 	t.cmd.Env = append(os.Environ(),
 		"TERM=xterm-256color",
 	)
+	t.cmd.Env = append(t.cmd.Env, t.opts.Env...)
 
 	var err error
 	t.ptmx, err = pty.StartWithSize(t.cmd, &pty.Winsize{
@@ -104,6 +185,17 @@ func (t *Terminal) Start() error {
 	// keep reading from PTY and feeding vt10x
 	go t.readLoop()
 
+	if t.opts.Init != "" {
+		script := t.opts.Init
+		if !strings.HasSuffix(script, "\n") {
+			script += "\n"
+		}
+		// the shell buffers this until it's ready to read, like typeahead
+		if _, err := t.ptmx.Write([]byte(script)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -119,15 +211,19 @@ func (t *Terminal) readLoop() {
 
 		t.mu.Lock()
 		if t.vt != nil {
-			t.vt.Write(buf[:n])
+			t.writeLocked(buf[:n])
 			t.dirty = true
 		}
+		if t.rec != nil {
+			t.recordLocked("o", t.rec.output(buf[:n]))
+		}
+		t.lastOutput = time.Now()
 		closed := t.closed
 		t.mu.Unlock()
 
 		// Broadcast to all subscribers
 		if !closed {
-			t.broadcast()
+			t.notify()
 		}
 	}
 }
@@ -144,7 +240,10 @@ func (t *Terminal) Write(data []byte) (int, error) {
 	return ptmx.Write(data)
 }
 
-func (t *Terminal) Render() string {
+// Render draws the screen with its colors and attributes, downsampled to
+// what the viewer's terminal supports. Each profile's output is cached
+// until the screen changes.
+func (t *Terminal) Render(profile termenv.Profile) string {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -152,75 +251,36 @@ func (t *Terminal) Render() string {
 		return ""
 	}
 
-	// Return cached render if not dirty
-	if !t.dirty && t.lastRender != "" {
-		return t.lastRender
+	if t.dirty || t.cells == nil {
+		t.damageLocked()
+		clear(t.renders)
+		t.dirty = false
+	}
+	if s, ok := t.renders[profile]; ok {
+		return s
 	}
 
 	cols, rows := t.vt.Size()
 	cursor := t.vt.Cursor()
 	cursorVisible := t.vt.CursorVisible()
+	cache := t.rowCacheLocked(profile, rows)
 
 	var sb strings.Builder
 	sb.Grow(cols * rows * 2)
 
-	// Track previous colors for run-length encoding
-	var prevFG, prevBG vt10x.Color
-	var inStyle bool
-
 	for y := 0; y < rows; y++ {
-		prevFG, prevBG = 0, 0
-		inStyle = false
-
-		for x := range cols {
-			cell := t.vt.Cell(x, y)
-			char := cell.Char
-			if char == 0 {
-				char = ' '
-			}
-
-			isCursor := cursorVisible && x == cursor.X && y == cursor.Y
-
-			fg := cell.FG
-			bg := cell.BG
-
-			if isCursor {
-				// Swap fg/bg for cursor (reverse video effect)
-				fg, bg = bg, fg
-			}
-
-			needsColorChange := fg != prevFG || bg != prevBG || (isCursor && !inStyle)
-
-			if needsColorChange {
-				if inStyle {
-					sb.WriteString("\x1b[0m")
-					inStyle = false
-				}
-
-				if fg != 0 && fg < 256 {
-					sb.WriteString(fgColor(fg))
-					inStyle = true
-				}
-				if bg != 0 && bg < 256 {
-					sb.WriteString(bgColor(bg))
-					inStyle = true
-				}
-				if isCursor && !inStyle {
-					// Fallback reverse video for cursor
-					sb.WriteString("\x1b[7m")
-					inStyle = true
-				}
-
-				prevFG, prevBG = fg, bg
-			}
-
-			sb.WriteRune(char)
+		cursorX := -1
+		if cursorVisible && y == cursor.Y {
+			cursorX = cursor.X
 		}
-
-		if inStyle {
-			sb.WriteString("\x1b[0m")
-			inStyle = false
+		// only rows that changed, or that the cursor moved in or out of,
+		// are drawn again
+		if !cache.valid[y] || cache.cursors[y] != cursorX {
+			var row strings.Builder
+			renderRow(&row, t.cells[y], cursorX, profile)
+			cache.rows[y], cache.cursors[y], cache.valid[y] = row.String(), cursorX, true
 		}
+		sb.WriteString(cache.rows[y])
 
 		if y < rows-1 {
 			sb.WriteString("\n")
@@ -228,28 +288,9 @@ func (t *Terminal) Render() string {
 	}
 
 	// Cache the result
-	t.lastRender = sb.String()
-	t.dirty = false
-
-	return t.lastRender
-}
-
-func fgColor(c vt10x.Color) string {
-	if c < 8 {
-		return fmt.Sprintf("\x1b[%dm", 30+c)
-	} else if c < 16 {
-		return fmt.Sprintf("\x1b[%dm", 90+(c-8))
-	}
-	return fmt.Sprintf("\x1b[38;5;%dm", c)
-}
-
-func bgColor(c vt10x.Color) string {
-	if c < 8 {
-		return fmt.Sprintf("\x1b[%dm", 40+c)
-	} else if c < 16 {
-		return fmt.Sprintf("\x1b[%dm", 100+(c-8))
-	}
-	return fmt.Sprintf("\x1b[48;5;%dm", c)
+	s := sb.String()
+	t.renders[profile] = s
+	return s
 }
 
 func (t *Terminal) Resize(width, height int) {
@@ -260,14 +301,21 @@ func (t *Terminal) Resize(width, height int) {
 		return
 	}
 
+	resized := width != t.width || height != t.height
 	t.width = width
 	t.height = height
 	t.dirty = true
-	t.lastRender = ""
 
 	if t.vt != nil {
 		t.vt.Resize(width, height)
 	}
+	if resized {
+		// every row is a different length now
+		t.cells = nil
+		clear(t.rowCaches)
+		t.scroll.resetRegion() // vt10x does the same
+		t.recordLocked("r", fmt.Sprintf("%dx%d", width, height))
+	}
 
 	if t.ptmx != nil {
 		pty.Setsize(t.ptmx, &pty.Winsize{
@@ -293,6 +341,7 @@ func (t *Terminal) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.stopRecordingLocked()
 	if t.ptmx != nil {
 		t.ptmx.Close()
 		t.ptmx = nil
@@ -305,6 +354,13 @@ func (t *Terminal) Close() error {
 	return nil
 }
 
+// LastOutput returns when the PTY last produced output.
+func (t *Terminal) LastOutput() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastOutput
+}
+
 func (t *Terminal) Size() (width, height int) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaypopat/duet/internal/room"
+)
+
+func (m *Model) isClassroom() bool {
+	return m.currentRoom != nil && m.currentRoom.Mode == room.ModeClassroom
+}
+
+// toggleMyShell flips a classroom participant between watching the
+// presenter and their own private shell
+func (m *Model) toggleMyShell() (tea.Model, tea.Cmd) {
+	if !m.isClassroom() {
+		return m, nil
+	}
+	if m.privateTab != "" {
+		return m.watchPresenter()
+	}
+
+	w, h := m.terminalSize()
+	tab, err := m.currentRoom.MyShell(m.clientID, w, h)
+	if err != nil {
+		m.addToast("Error: " + err.Error())
+		return m, nil
+	}
+	m.viewTab(tab)
+	m.privateTab = "my shell"
+	return m, m.waitForTerminalUpdate()
+}
+
+// watchPresenter goes back to the room's shared terminal
+func (m *Model) watchPresenter() (tea.Model, tea.Cmd) {
+	m.privateTab, m.peekClientID = "", ""
+	return m, m.startTerminal()
+}
+
+// openRoster shows the host everyone in the classroom
+func (m *Model) openRoster() (tea.Model, tea.Cmd) {
+	if !m.isClassroom() {
+		return m, nil
+	}
+	if !m.isHost {
+		m.addToast("Only the instructor can see the roster")
+		return m, nil
+	}
+	m.rosterOpen = true
+	m.rosterIdx = 0
+	return m, nil
+}
+
+// handleRosterKey moves through the roster; row 0 is the presenter terminal
+func (m *Model) handleRosterKey(key string) (tea.Model, tea.Cmd) {
+	roster, err := m.currentRoom.Roster(m.clientID)
+	if err != nil {
+		m.rosterOpen = false
+		m.addToast("Error: " + err.Error())
+		return m, nil
+	}
+
+	switch key {
+	case "up", "k":
+		m.rosterIdx = max(0, m.rosterIdx-1)
+	case "down", "j":
+		m.rosterIdx = min(len(roster), m.rosterIdx+1)
+	case "esc", "alt+v":
+		m.rosterOpen = false
+	case "enter":
+		m.rosterOpen = false
+		if m.rosterIdx == 0 || m.rosterIdx > len(roster) {
+			return m.watchPresenter()
+		}
+		student := roster[m.rosterIdx-1]
+		if !student.HasShell {
+			m.addToast(fmt.Sprintf("%s hasn't opened a shell yet", student.Username))
+			return m, nil
+		}
+		m.viewTab(student.Shell)
+		m.privateTab = student.Shell.Name
+		m.peekClientID = student.ClientID
+		return m, m.waitForTerminalUpdate()
+	}
+	return m, nil
+}
+
+// renderRoster draws the host's roster in place of the terminal
+func (m *Model) renderRoster(w, h int) string {
+	roster, _ := m.currentRoom.Roster(m.clientID)
+
+	var b strings.Builder
+	b.WriteString(m.styles.titleStyle.Render(fmt.Sprintf("students (%d)", len(roster))) + "\n\n")
+
+	rows := []string{"presenter terminal"}
+	for _, s := range roster {
+		status := "watching"
+		switch {
+		case s.InShell:
+			status = "in their shell"
+		case s.HasShell:
+			status = "watching, has a shell"
+		}
+		rows = append(rows, fmt.Sprintf("%s (%s)", s.Username, status))
+	}
+	for i, row := range rows {
+		if i == m.rosterIdx {
+			b.WriteString(m.styles.accentStyle.Bold(true).Render("▸ "+truncate(row, w-8)) + "\n")
+		} else {
+			b.WriteString(m.styles.textStyle.Render("  "+truncate(row, w-8)) + "\n")
+		}
+	}
+	b.WriteString("\n" + m.styles.dimStyle.Render("↑/↓ select • enter peek • esc close"))
+
+	return m.styles.terminalStyle.Width(w).Height(h).Render(b.String())
+}
@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaypopat/duet/internal/room"
+)
+
+// listenForRoomEvents waits until the room has events for us, then reads
+// everything we haven't seen yet in one go.
+func (m *Model) listenForRoomEvents() tea.Cmd {
+	if m.eventNotify == nil || m.currentRoom == nil {
+		return nil
+	}
+	notify, r, clientID := m.eventNotify, m.currentRoom, m.clientID
+	return func() tea.Msg {
+		if _, ok := <-notify; !ok {
+			return removedMsg{room: r, clientID: clientID}
+		}
+		events, resync := r.ReadEvents(clientID)
+		return roomEventsMsg{Events: events, Resync: resync}
+	}
+}
+
+// handleRemoved sends us back to the launch screen if the room removed us
+// without our session asking, e.g. a kick or the room being closed.
+func (m *Model) handleRemoved(msg removedMsg) {
+	// a leave we asked for, or a session that has since moved on
+	if msg.room != m.currentRoom || msg.clientID != m.clientID {
+		return
+	}
+	if ev, ok := msg.room.Removal(msg.clientID); ok {
+		if _, closed := m.handleRoomEvent(ev); closed {
+			return
+		}
+	}
+	m.cleanup()
+	m.screen = ScreenLaunch
+	m.notice = "You're no longer in the room"
+}
+
+// resyncRoomState rebuilds everything we derive from events after falling
+// too far behind the room's event log.
+func (m *Model) resyncRoomState() {
+	m.users = m.getUserList()
+	m.isHost = m.currentRoom.ClientRole(m.clientID) == room.RoleHost
+	m.typingUser = ""
+	m.controlRequester, m.controlRequesterID = "", ""
+	m.roomExpires, m.expiryReason = m.currentRoom.Expiry()
+	m.aiMessages = m.currentRoom.GetAIMessages()
+	m.syncAIViewportContent()
+	m.chatMessages = m.currentRoom.GetChat()
+	m.syncChatViewportContent()
+	m.syncInterviewState()
+}
+
+// handleRoomEvent applies one room event, reporting whether the room closed under us.
+func (m *Model) handleRoomEvent(ev room.RoomEvent) (cmd tea.Cmd, closed bool) {
+	switch ev := ev.(type) {
+	case room.JoinEvent:
+		m.users = m.getUserList()
+		if ev.ClientID != m.clientID {
+			m.addToast(fmt.Sprintf("%s joined", ev.Username))
+		}
+	case room.LeaveEvent:
+		m.users = m.getUserList()
+		if !ev.Removed {
+			m.addToast(fmt.Sprintf("%s left", ev.Username))
+		}
+		// don't leave the host watching someone who's gone
+		if ev.ClientID == m.peekClientID {
+			_, cmd := m.watchPresenter()
+			return cmd, false
+		}
+	case room.RoleEvent:
+		m.users = m.getUserList()
+		m.addToast(fmt.Sprintf("%s is now %s", ev.Username, ev.Role))
+	case room.DriverEvent:
+		if ev.ClientID != m.clientID {
+			m.controlRequester, m.controlRequesterID = "", ""
+		}
+		switch ev.ClientID {
+		case "":
+			m.addToast("Keyboard is free")
+		case m.clientID:
+			m.addToast("You have the keyboard")
+		default:
+			m.addToast(fmt.Sprintf("%s has the keyboard", ev.Username))
+		}
+	case room.ControlRequestEvent:
+		m.controlRequester = ev.Username
+		m.controlRequesterID = ev.ClientID
+	case room.ControlDeniedEvent:
+		m.addToast(fmt.Sprintf("%s kept the keyboard", ev.Username))
+	case room.ExpiringEvent:
+		m.roomExpires, m.expiryReason = ev.Deadline, ev.Reason
+		if !ev.Deadline.IsZero() {
+			m.addToast(fmt.Sprintf("Room closes in %s (%s)", time.Until(ev.Deadline).Round(time.Second), ev.Reason))
+		}
+	case room.ClosedEvent:
+		m.cleanup()
+		m.screen = ScreenLaunch
+		m.addToast(fmt.Sprintf("Room closed (%s)", ev.Reason))
+		return nil, true
+	case room.TypingEvent:
+		m.typingUser = ev.Username
+		m.typingTime = ev.At
+	case room.AISyncEvent:
+		// Another client updated the conversation - the event carries it
+		m.aiMessages = ev.Messages
+		m.syncAIViewportContent()
+		m.scrollToLastPrompt()
+	case room.ChatEvent:
+		m.chatMessages = append(m.chatMessages, ev.Message)
+		m.syncChatViewportContent()
+		if ev.Message.ClientID == m.clientID {
+			m.chatViewport.GotoBottom()
+			break
+		}
+		if !m.showAISidebar || m.sidebarTab != TabChat {
+			m.chatUnread++
+		}
+		if ev.Message.MentionsUser(m.name) {
+			m.addToast(fmt.Sprintf("@%s: %s", ev.Message.Username, truncate(ev.Message.Text, 60)))
+		}
+	case room.TabEvent:
+		return m.handleTabEvent(ev), false
+	case room.ViewingEvent:
+		m.users = m.getUserList()
+	case room.InterviewEvent:
+		m.handleInterviewEvent(ev)
+	case room.MobEvent:
+		m.handleMobEvent(ev)
+	case room.ModerationEvent:
+		return nil, m.handleModerationEvent(ev)
+	case room.KnockEvent:
+		m.addToast(fmt.Sprintf("%s is knocking", ev.Username))
+	case room.RecordingEvent:
+		if ev.Recording {
+			m.addToast(fmt.Sprintf("%s started recording the terminals", ev.Username))
+		} else {
+			m.addToast(fmt.Sprintf("%s stopped recording", ev.Username))
+		}
+	case room.HostEvent:
+		return m.handleHostEvent(ev), false
+	}
+	return nil, false
+}
+
+func (m *Model) handleHostEvent(ev room.HostEvent) tea.Cmd {
+	m.users = m.getUserList()
+	wasHost := m.isHost
+	m.isHost = ev.ClientID == m.clientID
+
+	switch {
+	case m.isHost:
+		m.addToast(fmt.Sprintf("You're now the host (was %s)", ev.Previous))
+	default:
+		m.addToast(fmt.Sprintf("%s is now the host", ev.Username))
+	}
+	// the interview summary is only shown to the host
+	m.syncProblemViewportContent()
+	if !wasHost || m.isHost {
+		return nil
+	}
+
+	// host-only views go with the role
+	m.peopleOpen, m.rosterOpen = false, false
+	m.pendingMod = pendingModeration{}
+	m.inviteToken = ""
+	if m.peekClientID != "" {
+		_, cmd := m.watchPresenter()
+		return cmd
+	}
+	return nil
+}
+
+func (m *Model) handleTabEvent(ev room.TabEvent) tea.Cmd {
+	// viewers' "currently viewing" labels use tab names
+	m.users = m.getUserList()
+
+	switch ev.Action {
+	case room.TabOpened:
+		if ev.ClientID != m.clientID {
+			m.addToast(fmt.Sprintf("New terminal: %s", ev.Name))
+		}
+	case room.TabClosed:
+		if ev.ClientID != m.clientID {
+			m.addToast(fmt.Sprintf("Terminal closed: %s", ev.Name))
+		}
+		// our tab is gone - fall back to the first one
+		if ev.TabID == m.tabID {
+			tabs := m.currentRoom.Tabs()
+			if len(tabs) == 0 {
+				return nil
+			}
+			m.terminal, m.termUpdateCh = nil, nil // Close already dropped our subscription
+			m.viewTab(tabs[0])
+			return m.waitForTerminalUpdate()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaypopat/duet/internal/room"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// isInterviewHost reports whether we can pin problems and run the timer
+func (m *Model) isInterviewHost() bool {
+	if m.currentRoom == nil || m.currentRoom.Mode != room.ModeInterview {
+		return false
+	}
+	if !m.isHost {
+		m.addToast("Only the interviewer can do that")
+		return false
+	}
+	return true
+}
+
+// submitInterviewInput handles the pin-problem and start-timer prompts
+func (m *Model) submitInterviewInput(mode InputMode, text string) (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
+	}
+
+	if mode == ModeTimer {
+		minutes, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || minutes <= 0 {
+			m.addToast("Enter a number of minutes")
+			return m, nil
+		}
+		if err := m.currentRoom.StartInterview(m.clientID, time.Duration(minutes)*time.Minute); err != nil {
+			m.addToast("Error: " + err.Error())
+		}
+		return m, nil
+	}
+
+	problem := text
+	if name, ok := strings.CutPrefix(text, "@"); ok {
+		// only files under the template's directory, symlinks included, so
+		// hosts can't read arbitrary files off the server
+		dir := m.currentRoom.ProblemDir()
+		if dir == "" {
+			m.addToast("Problem files need a room template with a directory")
+			return m, nil
+		}
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			m.addToast("Error: " + err.Error())
+			return m, nil
+		}
+		data, err := root.ReadFile(name)
+		root.Close()
+		if err != nil {
+			m.addToast("Error: " + err.Error())
+			return m, nil
+		}
+		problem = string(data)
+	}
+	if err := m.currentRoom.PinProblem(m.clientID, problem); err != nil {
+		m.addToast("Error: " + err.Error())
+	}
+	return m, nil
+}
+
+// syncInterviewState reloads the problem and timer from the room
+func (m *Model) syncInterviewState() {
+	m.interviewDeadline = time.Time{}
+	if m.currentRoom == nil || m.currentRoom.Mode != room.ModeInterview {
+		return
+	}
+	if _, deadline, running := m.currentRoom.InterviewStatus(); running {
+		m.interviewDeadline = deadline
+	}
+	m.syncProblemViewportContent()
+}
+
+func (m *Model) handleInterviewEvent(ev room.InterviewEvent) {
+	switch ev.Action {
+	case room.InterviewProblemPinned:
+		m.syncProblemViewportContent()
+		m.problemViewport.GotoTop()
+		m.addToast("Problem statement pinned (ctrl+b to view)")
+	case room.InterviewStarted:
+		m.interviewDeadline = ev.Deadline
+		m.summaryPath = ""
+		m.syncProblemViewportContent()
+		m.addToast(fmt.Sprintf("Interview started: %s", time.Until(ev.Deadline).Round(time.Minute)))
+	case room.InterviewEnded:
+		m.interviewDeadline = time.Time{}
+		m.addToast("Interview over")
+		if m.isHost {
+			m.summaryPath = ev.SummaryPath
+			m.syncProblemViewportContent()
+			m.problemViewport.GotoTop()
+			m.addToast("Interview summary is in the problem pane (ctrl+b)")
+		}
+	}
+}
+
+// rebuilds the problem pane from the room's pinned statement, or for the
+// host, the summary of the interview that just ended, which includes it
+func (m *Model) syncProblemViewportContent() {
+	if m.currentRoom == nil {
+		return
+	}
+	m.problemViewport.SetContent(m.renderMarkdown(m.problemPaneContent(), m.problemViewport.Width))
+}
+
+// problemPaneContent is the markdown the problem pane shows
+func (m *Model) problemPaneContent() string {
+	if m.isHost {
+		if summary, err := m.currentRoom.Summary(m.clientID); err == nil && summary != "" {
+			return summary
+		}
+	}
+	return m.currentRoom.Problem()
+}
+
+func (m *Model) renderProblemTab(b *strings.Builder) {
+	if m.currentRoom == nil || m.problemPaneContent() == "" {
+		hint := "No problem pinned yet."
+		if m.isHost {
+			hint += "\nPress alt+p to pin one."
+		}
+		b.WriteString(m.styles.dimStyle.Render(hint))
+		return
+	}
+	b.WriteString(m.problemViewport.View())
+	scrollInfo := fmt.Sprintf(" %.0f%% ", m.problemViewport.ScrollPercent()*100)
+	b.WriteString("\n" + m.styles.dimStyle.Render(scrollInfo))
+}
+
+// renderMarkdown does just enough markdown for problem statements:
+// headings, bullets and fenced code
+func (m *Model) renderMarkdown(md string, maxWidth int) string {
+	if maxWidth <= 0 {
+		maxWidth = 40
+	}
+
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inCode = !inCode
+			continue
+		case inCode:
+			b.WriteString(m.styles.accentStyle.Render("  "+truncate(line, maxWidth-2)) + "\n")
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			b.WriteString(m.styles.titleStyle.Render(truncate(heading, maxWidth)) + "\n")
+			continue
+		}
+
+		indent := ""
+		if rest, ok := strings.CutPrefix(trimmed, "- "); ok {
+			trimmed, indent = "• "+rest, "  "
+		} else if rest, ok := strings.CutPrefix(trimmed, "* "); ok {
+			trimmed, indent = "• "+rest, "  "
+		}
+		for i, l := range strings.Split(wordwrap.String(trimmed, maxWidth-len(indent)), "\n") {
+			if i > 0 {
+				l = indent + l
+			}
+			b.WriteString(m.styles.textStyle.Render(l) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
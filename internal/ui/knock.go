@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jaypopat/duet/internal/room"
+)
+
+// waitForKnock waits for the host to answer, or the room to close
+func waitForKnock(k *room.Knock) tea.Cmd {
+	return func() tea.Msg {
+		approved, ok := <-k.Answer()
+		return KnockAnsweredMsg{Knock: k, Approved: approved, Closed: !ok}
+	}
+}
+
+func (m *Model) knockAnswered(msg KnockAnsweredMsg) (tea.Model, tea.Cmd) {
+	// we gave up waiting since
+	if m.knock != msg.Knock {
+		return m, nil
+	}
+	r := m.knockRoom
+	m.knock, m.knockRoom = nil, nil
+
+	if !msg.Approved {
+		m.screen = ScreenLaunch
+		m.notice = "The host didn't let you in"
+		if msg.Closed {
+			m.notice = "The room closed while you were waiting"
+		}
+		return m, nil
+	}
+
+	role := room.RoleDriver
+	if m.joinObserver {
+		role = room.RoleObserver
+	}
+	return m, func() tea.Msg {
+		m.registerAsClient(r, role)
+		return RoomJoinedMsg{RoomID: r.ID, Room: r}
+	}
+}
+
+// cancelKnock withdraws our request if we're still waiting
+func (m *Model) cancelKnock() {
+	if m.knock != nil {
+		m.knockRoom.CancelKnock(m.knock.ID)
+		m.knock, m.knockRoom = nil, nil
+	}
+}
+
+// pendingKnock is the host's prompt for the first person waiting, if any
+func (m *Model) pendingKnock() string {
+	if m.currentRoom == nil || !m.isHost {
+		return ""
+	}
+	knocks := m.currentRoom.Knocks()
+	if len(knocks) == 0 {
+		return ""
+	}
+	k := knocks[0]
+	key := "no key"
+	if !strings.HasPrefix(k.Identity, "user:") {
+		key = "key " + k.Identity
+	}
+	prompt := fmt.Sprintf("%s (%s) wants to join • ctrl+y let in • ctrl+n turn away", k.Username, key)
+	if len(knocks) > 1 {
+		prompt += fmt.Sprintf(" • %d more waiting", len(knocks)-1)
+	}
+	return prompt
+}
+
+func (m *Model) viewWaiting() string {
+	title := m.styles.titleStyle.Render("Knock knock")
+	msg := m.styles.textStyle.Render("Waiting for the host to let you in...")
+	help := m.styles.helpStyle.Render("esc give up")
+
+	content := lipgloss.JoinVertical(lipgloss.Center, title, "", msg, "", help)
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
+}
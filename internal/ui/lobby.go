@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jaypopat/duet/internal/room"
+)
+
+// refreshLobby reloads the public rooms matching the filter, keeping the
+// selection on the same room when it's still listed
+func (m *Model) refreshLobby() {
+	var selected string
+	if m.lobbyIdx < len(m.lobbyRooms) {
+		selected = m.lobbyRooms[m.lobbyIdx].ID
+	}
+
+	filter := strings.TrimSpace(m.input.Value())
+	type scored struct {
+		room  room.RoomSummary
+		score int
+	}
+	var matches []scored
+	for _, r := range m.roomManager.PublicRooms() {
+		text := strings.Join(append([]string{r.ID, r.Description, r.Host}, r.Tags...), " ")
+		if score, ok := fuzzyScore(filter, text); ok {
+			matches = append(matches, scored{r, score})
+		}
+	}
+	// PublicRooms is newest first; keep that order among equal scores
+	slices.SortStableFunc(matches, func(a, b scored) int { return b.score - a.score })
+
+	m.lobbyRooms = m.lobbyRooms[:0]
+	m.lobbyIdx = 0
+	for i, s := range matches {
+		m.lobbyRooms = append(m.lobbyRooms, s.room)
+		if s.room.ID == selected {
+			m.lobbyIdx = i
+		}
+	}
+}
+
+func (m *Model) handleLobbyKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "ctrl+p":
+		if m.lobbyIdx > 0 {
+			m.lobbyIdx--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.lobbyIdx < len(m.lobbyRooms)-1 {
+			m.lobbyIdx++
+		}
+		return m, nil
+	case "enter":
+		if m.lobbyIdx >= len(m.lobbyRooms) {
+			return m, nil
+		}
+		id := m.lobbyRooms[m.lobbyIdx].ID
+		return m, func() tea.Msg { return m.openRoom(id, "") }
+	case "esc":
+		return m, gotoScreen(ScreenLaunch)
+	}
+
+	before := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != before {
+		m.lobbyIdx = 0
+		m.refreshLobby()
+	}
+	return m, cmd
+}
+
+func (m *Model) viewLobby() string {
+	title := m.styles.titleStyle.Render("Public Rooms")
+	filter := m.styles.inputBoxStyle.Render(m.input.View())
+	help := m.styles.helpStyle.Render("type to filter • ↑/↓ select • enter join • esc back")
+
+	width := min(m.width-4, 90)
+	var rows []string
+	if len(m.lobbyRooms) == 0 {
+		rows = append(rows, m.styles.dimStyle.Render("No public rooms yet."))
+	}
+	// leave room for the title, filter box and help
+	visible := max(1, (m.height-12)/2)
+	start := max(0, m.lobbyIdx-visible+1)
+	for i := start; i < len(m.lobbyRooms) && i < start+visible; i++ {
+		rows = append(rows, m.renderLobbyRow(m.lobbyRooms[i], i == m.lobbyIdx, width)...)
+	}
+
+	// e.g. we're banned, or the room closed since it was listed
+	var errorLine string
+	if len(m.toasts) > 0 {
+		errorLine = m.styles.errorStyle.Render("▸ " + m.toasts[len(m.toasts)-1].text)
+	}
+
+	list := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	content := lipgloss.JoinVertical(lipgloss.Center, title, "", filter, "", list, "", errorLine, help)
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
+}
+
+// renderLobbyRow draws a room as two lines: description, then details
+func (m *Model) renderLobbyRow(r room.RoomSummary, selected bool, width int) []string {
+	desc := r.Description
+	if desc == "" {
+		desc = r.ID
+	}
+	if r.Protected {
+		desc += " (password)"
+	}
+	people := "people"
+	if r.Participants == 1 {
+		people = "person"
+	}
+	details := fmt.Sprintf("%s • %s • host %s • %d %s • %s old",
+		r.ID, r.Mode, r.Host, r.Participants, people, time.Since(r.CreatedAt).Round(time.Minute))
+	if len(r.Tags) > 0 {
+		details += " • #" + strings.Join(r.Tags, " #")
+	}
+
+	marker, style := "  ", m.styles.textStyle
+	if selected {
+		marker, style = "▸ ", m.styles.accentStyle.Bold(true)
+	}
+	return []string{
+		style.Render(marker + truncate(desc, width-2)),
+		m.styles.dimStyle.Render("  " + truncate(details, width-2)),
+	}
+}
+
+// fuzzyScore matches pattern against text as a case-insensitive
+// subsequence, scoring runs of consecutive characters and matches at the
+// start of words higher. An empty pattern matches everything.
+func fuzzyScore(pattern, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+
+	score, pi, run := 0, 0, 0
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if p[pi] == ' ' {
+			pi++ // spaces in the pattern just separate words
+			run = 0
+			if pi == len(p) {
+				break
+			}
+		}
+		if t[ti] != p[pi] {
+			run = 0
+			continue
+		}
+		run++
+		score += run
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		pi++
+	}
+	return score, pi == len(p)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaypopat/duet/internal/room"
+)
+
+func (m *Model) isMob() bool {
+	return m.currentRoom != nil && m.currentRoom.Mode == room.ModeMob
+}
+
+// skipTurn hands the keyboard to the next driver in the queue now
+func (m *Model) skipTurn() (tea.Model, tea.Cmd) {
+	if !m.isMob() {
+		return m, nil
+	}
+	if err := m.currentRoom.SkipTurn(m.clientID); err != nil {
+		m.addToast("Error: " + err.Error())
+	}
+	return m, nil
+}
+
+// moveInQueue moves us one place earlier (alt+up) or later (alt+down) in the queue
+func (m *Model) moveInQueue(key string) (tea.Model, tea.Cmd) {
+	if !m.isMob() {
+		return m, nil
+	}
+	delta := 1
+	if key == "alt+up" {
+		delta = -1
+	}
+	if err := m.currentRoom.MoveInQueue(m.clientID, delta); err != nil {
+		m.addToast("Error: " + err.Error())
+	}
+	return m, nil
+}
+
+func (m *Model) setRotation(text string) (tea.Model, tea.Cmd) {
+	minutes, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || minutes <= 0 {
+		m.addToast("Enter a number of minutes")
+		return m, nil
+	}
+	if err := m.currentRoom.SetRotation(m.clientID, time.Duration(minutes)*time.Minute); err != nil {
+		m.addToast("Error: " + err.Error())
+		return m, nil
+	}
+	m.addToast(fmt.Sprintf("Turns now last %d min", minutes))
+	return m, nil
+}
+
+func (m *Model) handleMobEvent(ev room.MobEvent) {
+	if ev.Action != room.MobRotated {
+		return // the sidebar reads the queue live
+	}
+	if ev.SkippedBy != "" {
+		m.addToast(fmt.Sprintf("%s skipped ahead", ev.SkippedBy))
+	}
+	if ev.DriverID == m.clientID {
+		m.addToast(fmt.Sprintf("Your turn at the keyboard (%s next)", ev.Next))
+		return
+	}
+	m.addToast(fmt.Sprintf("Handover: %s's turn, %s next", ev.Driver, ev.Next))
+}
+
+// renderMobQueue shows the turn countdown and who's up next
+func (m *Model) renderMobQueue(w int) string {
+	queue, turnEnds, rotation := m.currentRoom.MobStatus()
+
+	var b strings.Builder
+	if turnEnds.IsZero() {
+		b.WriteString(m.styles.dimStyle.Render(fmt.Sprintf("turns: %s, waiting for more", rotation)) + "\n")
+	} else {
+		left := max(0, time.Until(turnEnds).Round(time.Second))
+		b.WriteString(m.styles.dimStyle.Render("turn ends in: ") + m.styles.successStyle.Render(left.String()) + "\n")
+	}
+	if len(queue) > 1 {
+		b.WriteString(m.styles.dimStyle.Render("up next:") + "\n")
+		for i, t := range queue[1:] {
+			name := t.Username
+			if t.ClientID == m.clientID {
+				name += " (you)"
+			}
+			b.WriteString(m.styles.textStyle.Render(truncate(fmt.Sprintf("  %d. %s", i+1, name), w-4)) + "\n")
+		}
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -29,30 +30,93 @@ type Model struct {
 	width    int
 	height   int
 	username string
+	name     string // username as the current room shows it, made unique there
 	clientID string
-
-	selected int
-	input    textinput.Model
-
-	roomID       string
-	currentRoom  *room.Room
-	terminal     *terminal.Terminal
-	termUpdateCh chan struct{}
-	termContent  string
-	users        []string
-	toasts       []toast
-	inputMode    InputMode
-	cmdInput     textinput.Model
-	typingUser   string
-	typingTime   time.Time
+	identity string // key fingerprint when available, used to resume after a drop
+	addr     string // client IP address, for limiting failed joins
+	mouseOn  bool   // mouse reporting is enabled; see syncMouse
+
+	selected    int
+	input       textinput.Model
+	secretInput textinput.Model
+	tagsInput   textinput.Model
+	isPublic    bool // list the new room in the lobby
+	approval    bool // make joiners knock
+	createFocus createField
+	templateIdx int // 0 = bare shell, i = roomManager.Templates()[i-1]
+	modeIdx     int // index into room.Modes
+
+	lobbyRooms []room.RoomSummary // public rooms matching the filter in m.input
+	lobbyIdx   int
+
+	replayList []room.RecordingInfo // recordings for the room code in m.input
+	replayIdx  int
+	replayErr  string
+	replay     *replayPlayer
+	replayGen  int // bumped to stop the old player's tick loop
+
+	roomID        string
+	pendingRoomID string // room awaiting credentials on ScreenJoinAuth
+	knock         *room.Knock
+	knockRoom     *room.Room // room we're waiting to be let into
+	currentRoom   *room.Room
+	isHost        bool
+	joinObserver  bool // join the next room read-only
+	inviteToken   string
+	inviteExpires time.Time
+	terminal      *terminal.Terminal
+	tabID         string // terminal tab we're viewing
+	termUpdateCh  chan struct{}
+	termContent   string
+	scrolling     bool   // looking at history instead of live output
+	scrollOffset  int    // lines back from the bottom of history
+	scrollTotal   uint64 // history lines seen last render, to hold our place
+	users         []participant
+	toasts        []toast
+	inputMode     InputMode
+	cmdInput      textinput.Model
+	typingUser    string
+	typingTime    time.Time
+
+	// pending request for the keyboard, shown to us while we're driving
+	controlRequester   string
+	controlRequesterID string
+
+	// set when the reaper has warned that the room is about to close
+	roomExpires  time.Time
+	expiryReason string
 
 	showAISidebar    bool
 	aiViewport       viewport.Model
 	aiLoading        bool
 	aiSpinner        spinner.Model
 	lastPromptOffset int
+	aiMessages       []AIMessage // latest conversation, kept in sync by AISyncEvent
+
+	sidebarTab   SidebarTab
+	chatViewport viewport.Model
+	chatMessages []room.ChatMessage
+	chatUnread   int
+
+	// interview rooms: pinned problem and countdown
+	problemViewport   viewport.Model
+	interviewDeadline time.Time // zero unless the timer is running
+	summaryPath       string    // where the last interview summary went (host only)
+
+	// classroom rooms: a private shell we're looking at instead of a shared tab
+	privateTab   string // its name for the header, "" on a shared tab
+	peekClientID string // student whose shell the host is peeking at
+	rosterOpen   bool
+	rosterIdx    int
+
+	// host's participant management panel
+	peopleOpen bool
+	peopleIdx  int
+	pendingMod pendingModeration // kick/ban waiting on a reason
 
-	eventChan chan room.RoomEvent
+	notice string // why we were sent back to the launch screen, shown there
+
+	eventNotify <-chan struct{}
 
 	roomManager *room.Manager
 	aiClient    *ai.Client
@@ -65,11 +129,24 @@ type toast struct {
 	expires time.Time
 }
 
-func New(renderer *lipgloss.Renderer, roomManager *room.Manager, workerURL, username string) *Model {
+// New creates the UI model for one SSH session. fingerprint is the session's
+// public key fingerprint, or empty for keyboard-interactive logins. addr is
+// the client's IP address, which failed joins are counted against.
+func New(renderer *lipgloss.Renderer, roomManager *room.Manager, workerURL, username, fingerprint, addr string) *Model {
 	ti := textinput.New()
 	ti.CharLimit = 100
 	ti.Width = 40
 
+	secretInput := textinput.New()
+	secretInput.CharLimit = 100
+	secretInput.Width = 40
+	secretInput.EchoMode = textinput.EchoPassword
+	secretInput.EchoCharacter = '•'
+
+	tagsInput := textinput.New()
+	tagsInput.CharLimit = 100
+	tagsInput.Width = 40
+
 	cmdInput := textinput.New()
 	cmdInput.CharLimit = 500
 	cmdInput.Width = 60
@@ -89,26 +166,44 @@ func New(renderer *lipgloss.Renderer, roomManager *room.Manager, workerURL, user
 		username = "guest"
 	}
 
+	identity := fingerprint
+	if identity == "" {
+		identity = "user:" + username
+	}
+
 	aiVP := viewport.New(40, 20)
 	aiVP.Style = lipgloss.NewStyle()
 
+	chatVP := viewport.New(40, 20)
+	chatVP.Style = lipgloss.NewStyle()
+
+	problemVP := viewport.New(40, 20)
+	problemVP.Style = lipgloss.NewStyle()
+
 	return &Model{
-		screen:        ScreenLaunch,
-		username:      username,
-		clientID:      uuid.New().String(),
-		input:         ti,
-		cmdInput:      cmdInput,
-		users:         []string{},
-		toasts:        []toast{},
-		inputMode:     ModeNormal,
-		roomManager:   roomManager,
-		aiClient:      aiClient,
-		showAISidebar: true,
-		aiViewport:    aiVP,
-		aiSpinner:     s,
-		aiLoading:     false,
-		renderer:      renderer,
-		styles:        styles,
+		screen:          ScreenLaunch,
+		username:        username,
+		name:            username,
+		clientID:        uuid.New().String(),
+		identity:        identity,
+		addr:            addr,
+		input:           ti,
+		secretInput:     secretInput,
+		tagsInput:       tagsInput,
+		cmdInput:        cmdInput,
+		users:           []participant{},
+		toasts:          []toast{},
+		inputMode:       ModeNormal,
+		roomManager:     roomManager,
+		aiClient:        aiClient,
+		showAISidebar:   true,
+		aiViewport:      aiVP,
+		chatViewport:    chatVP,
+		problemViewport: problemVP,
+		aiSpinner:       s,
+		aiLoading:       false,
+		renderer:        renderer,
+		styles:          styles,
 	}
 }
 
@@ -150,6 +245,11 @@ func tickCmd() tea.Cmd {
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	return model, tea.Batch(cmd, m.syncMouse())
+}
+
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -166,12 +266,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			vpW, vpH := m.aiViewportInnerSize(aiSidebarW, mainH)
 			m.aiViewport.Width = vpW
 			m.aiViewport.Height = vpH
+			m.chatViewport.Width = vpW
+			m.chatViewport.Height = vpH
+			m.syncChatViewportContent()
+			m.problemViewport.Width = vpW
+			m.problemViewport.Height = vpH
+			m.syncProblemViewportContent()
 		}
 		return m, nil
 
 	case tea.KeyMsg:
 		return m.handleKey(msg)
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case spinner.TickMsg:
 		if m.aiLoading {
 			var cmd tea.Cmd
@@ -181,47 +290,72 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tickMsg:
 		m.expireToasts()
+		if m.currentRoom != nil {
+			m.currentRoom.ReleaseIdleDriver()
+		}
+		if m.screen == ScreenLobby {
+			m.refreshLobby()
+		}
 		if m.typingUser != "" && time.Since(m.typingTime) > 2*time.Second {
 			m.typingUser = ""
 		}
 		return m, tickCmd()
 
 	case terminalUpdateMsg:
-		if m.terminal != nil {
-			m.termContent = m.terminal.Render()
-		}
+		m.refreshTerminal()
 		return m, m.waitForTerminalUpdate()
 
-	case roomEventMsg:
-		switch msg.Event.Type {
-		case "join":
-			m.users = append(m.users, msg.Event.Username)
-			if msg.Event.Username != m.username {
-				m.addToast(fmt.Sprintf("%s joined", msg.Event.Username))
+	case removedMsg:
+		m.handleRemoved(msg)
+		return m, nil
+
+	case roomEventsMsg:
+		if msg.Resync {
+			m.resyncRoomState()
+		}
+		cmds := []tea.Cmd{}
+		for _, ev := range msg.Events {
+			cmd, closed := m.handleRoomEvent(ev)
+			if closed {
+				return m, nil
 			}
-		case "leave":
-			m.users = removeUser(m.users, msg.Event.Username)
-			m.addToast(fmt.Sprintf("%s left", msg.Event.Username))
-		case "typing":
-			m.typingUser = msg.Event.Username
-			m.typingTime = time.Now()
-		case "ai_sync":
-			// Another client updated AI messages - refresh viewport from shared Room
-			m.syncAIViewportContent()
-			m.scrollToLastPrompt()
-		}
-		return m, m.listenForRoomEvents()
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(append(cmds, m.listenForRoomEvents())...)
 
 	case GotoScreenMsg:
 		return m.gotoScreen(msg.Screen)
 
+	case replayTickMsg:
+		if m.replay == nil || msg.gen != m.replayGen {
+			return m, nil
+		}
+		m.replay.tick(time.Now())
+		if m.replay.paused || m.replay.finished() {
+			m.replay.ticking = false
+			return m, nil
+		}
+		return m, m.replayTick()
+
 	case RoomCreatedMsg:
 		m.roomID = msg.RoomID
 		m.currentRoom = msg.Room
 		m.screen = ScreenRoomCreated
-		m.users = []string{m.username + " (host)"}
+		m.users = m.getUserList()
 		return m, nil
 
+	case CredentialsRequiredMsg:
+		m.pendingRoomID = msg.RoomID
+		return m.gotoScreen(ScreenJoinAuth)
+
+	case KnockingMsg:
+		m.knock, m.knockRoom = msg.Knock, msg.Room
+		m.screen = ScreenWaiting
+		return m, waitForKnock(msg.Knock)
+
+	case KnockAnsweredMsg:
+		return m.knockAnswered(msg)
+
 	case RoomJoinedMsg:
 		m.roomID = msg.RoomID
 		m.currentRoom = msg.Room
@@ -229,8 +363,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.users = m.getUserList()
 
 		// Sync AI viewport with existing room messages (history for late joiners)
+		m.aiMessages = msg.Room.GetAIMessages()
 		m.syncAIViewportContent()
 		m.aiViewport.GotoBottom() // For history, show the most recent
+		m.chatMessages = msg.Room.GetChat()
+		m.syncChatViewportContent()
+		m.syncInterviewState()
 
 		// start terminal and event listening
 		return m, tea.Batch(
@@ -249,12 +387,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case AIResponseMsg:
 		if m.currentRoom != nil {
-			m.currentRoom.SetAIMessages(msg.Messages)
-			// Notify other clients to sync their viewport
-			m.currentRoom.BroadcastEvent(room.RoomEvent{
-				Type: "ai_sync",
-			}, m.clientID)
+			if err := m.currentRoom.SetAIMessages(msg.Messages); err != nil {
+				m.addToast("Error: " + err.Error())
+			}
+			// Hand the new conversation to other clients so they don't have to reread it
+			m.currentRoom.BroadcastEvent(room.AISyncEvent{Messages: msg.Messages}, m.clientID)
 		}
+		m.aiMessages = msg.Messages
 		m.syncAIViewportContent()
 		m.scrollToLastPrompt()
 
@@ -270,10 +409,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	if m.screen == ScreenCreate || m.screen == ScreenJoin {
-		var cmd tea.Cmd
-		m.input, cmd = m.input.Update(msg)
-		return m, cmd
+	if m.screen == ScreenCreate || m.screen == ScreenJoin || m.screen == ScreenJoinAuth {
+		return m, m.updateFocusedInput(msg)
 	}
 
 	if m.screen == ScreenRoom {
@@ -303,18 +440,27 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.selected--
 			}
 		case "down", "j":
-			if m.selected < 1 {
+			if m.selected < 3 {
 				m.selected++
 			}
 		case "c", "C":
 			return m, gotoScreen(ScreenCreate)
 		case "J":
 			return m, gotoScreen(ScreenJoin)
+		case "l", "L":
+			return m, gotoScreen(ScreenLobby)
+		case "r", "R":
+			return m, gotoScreen(ScreenReplays)
 		case "enter":
-			if m.selected == 0 {
+			switch m.selected {
+			case 0:
 				return m, gotoScreen(ScreenCreate)
+			case 1:
+				return m, gotoScreen(ScreenJoin)
+			case 2:
+				return m, gotoScreen(ScreenLobby)
 			}
-			return m, gotoScreen(ScreenJoin)
+			return m, gotoScreen(ScreenReplays)
 		case "q", "esc":
 			return m, tea.Quit
 		}
@@ -325,10 +471,59 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, m.createRoom
 		case "esc":
 			return m, gotoScreen(ScreenLaunch)
+		case "tab":
+			return m, m.cycleCreateFocus(1)
+		case "shift+tab":
+			return m, m.cycleCreateFocus(-1)
+		case "left", "right":
+			step := 1
+			if key == "left" {
+				step = -1
+			}
+			switch m.createFocus {
+			case fieldPublic:
+				m.isPublic = !m.isPublic
+				return m, nil
+			case fieldApproval:
+				m.approval = !m.approval
+				return m, nil
+			case fieldMode:
+				n := len(room.Modes)
+				m.modeIdx = (m.modeIdx + step + n) % n
+				return m, nil
+			case fieldTemplate:
+				n := len(m.roomManager.Templates()) + 1
+				m.templateIdx = (m.templateIdx + step + n) % n
+				return m, nil
+			}
+			return m, m.updateFocusedInput(msg)
+		case " ":
+			switch m.createFocus {
+			case fieldPublic:
+				m.isPublic = !m.isPublic
+				return m, nil
+			case fieldApproval:
+				m.approval = !m.approval
+				return m, nil
+			}
+			return m, m.updateFocusedInput(msg)
 		default:
-			var cmd tea.Cmd
-			m.input, cmd = m.input.Update(msg)
-			return m, cmd
+			return m, m.updateFocusedInput(msg)
+		}
+
+	case ScreenLobby:
+		return m.handleLobbyKey(key, msg)
+
+	case ScreenReplays:
+		return m.handleReplaysKey(key, msg)
+
+	case ScreenReplay:
+		return m.handleReplayKey(key)
+
+	case ScreenWaiting:
+		if key == "esc" {
+			m.cancelKnock()
+			return m, gotoScreen(ScreenLaunch)
 		}
 
 	case ScreenJoin:
@@ -337,12 +532,26 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, m.joinRoom
 		case "esc":
 			return m, gotoScreen(ScreenLaunch)
+		case "tab":
+			m.joinObserver = !m.joinObserver
+			return m, nil
 		default:
 			var cmd tea.Cmd
 			m.input, cmd = m.input.Update(msg)
 			return m, cmd
 		}
 
+	case ScreenJoinAuth:
+		switch key {
+		case "enter":
+			return m, m.joinRoomWithCredential
+		case "esc":
+			m.pendingRoomID = ""
+			return m, gotoScreen(ScreenJoin)
+		default:
+			return m, m.updateFocusedInput(msg)
+		}
+
 	case ScreenRoomCreated:
 		switch key {
 		case "enter":
@@ -364,6 +573,15 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleRoomKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.rosterOpen {
+		return m.handleRosterKey(key)
+	}
+	if m.peopleOpen && m.inputMode == ModeNormal {
+		return m.handlePeopleKey(key)
+	}
+	if m.scrolling && m.inputMode == ModeNormal && m.handleScrollKey(key) {
+		return m, nil
+	}
 	if m.inputMode != ModeNormal {
 		switch key {
 		case "enter":
@@ -381,16 +599,25 @@ func (m *Model) handleRoomKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch key {
 	case "ctrl+g":
+		if !m.canWrite() {
+			m.addToast("Observers can't prompt the AI")
+			return m, nil
+		}
 		if m.aiClient == nil {
 			m.addToast("AI not configured (no worker URL)")
 			return m, nil
 		}
 		m.inputMode = ModeAI
+		m.sidebarTab = TabAI
 		m.cmdInput.Reset()
 		m.cmdInput.Placeholder = "Ask the AI..."
 		m.cmdInput.Focus()
 		return m, textinput.Blink
 	case "ctrl+r":
+		if !m.canWrite() {
+			m.addToast("Observers can't run sandbox commands")
+			return m, nil
+		}
 		if m.aiClient == nil {
 			m.addToast("Sandbox not configured (no worker URL)")
 			return m, nil
@@ -400,25 +627,159 @@ func (m *Model) handleRoomKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.cmdInput.Placeholder = "Command to run..."
 		m.cmdInput.Focus()
 		return m, textinput.Blink
+	case "ctrl+e":
+		m.inputMode = ModeChat
+		m.showAISidebar = true
+		m.setSidebarTab(TabChat)
+		m.cmdInput.Reset()
+		m.cmdInput.Placeholder = "Message the room (@name to mention)..."
+		m.cmdInput.Focus()
+		return m, textinput.Blink
+	case "ctrl+b":
+		tabs := m.sidebarTabs()
+		next := 0
+		for i, t := range tabs {
+			if t == m.sidebarTab {
+				next = (i + 1) % len(tabs)
+			}
+		}
+		m.setSidebarTab(tabs[next])
+		return m, nil
 	case "ctrl+a":
 		m.showAISidebar = !m.showAISidebar
+		if m.showAISidebar && m.sidebarTab == TabChat {
+			m.chatUnread = 0
+		}
 		return m, nil
 	case "ctrl+j":
 		if m.showAISidebar {
-			m.aiViewport.ScrollDown(3)
+			m.activeSidebarViewport().ScrollDown(3)
 		}
 		return m, nil
 	case "ctrl+k":
 		if m.showAISidebar {
-			m.aiViewport.ScrollUp(3)
+			m.activeSidebarViewport().ScrollUp(3)
 		}
 		return m, nil
+	case "ctrl+t":
+		return m.mintInvite()
+	case "alt+t":
+		return m.openTab()
+	case "alt+w":
+		return m.closeTab()
+	case "alt+r":
+		if !m.canWrite() {
+			m.addToast("Observers can't rename terminals")
+			return m, nil
+		}
+		m.inputMode = ModeRenameTab
+		m.cmdInput.Reset()
+		m.cmdInput.Placeholder = "New name for this terminal..."
+		m.cmdInput.Focus()
+		return m, textinput.Blink
+	case "alt+left":
+		return m.switchTab(m.currentTabIndex() - 1)
+	case "alt+right":
+		return m.switchTab(m.currentTabIndex() + 1)
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		return m.switchTab(int(key[len(key)-1] - '1'))
+	case "ctrl+o":
+		return m.toggleControl()
+	case "ctrl+y", "ctrl+n":
+		if m.currentRoom == nil {
+			return m, nil
+		}
+		// people at the door come before keyboard requests
+		if knocks := m.currentRoom.Knocks(); m.isHost && len(knocks) > 0 {
+			if err := m.currentRoom.AnswerKnock(m.clientID, knocks[0].ID, key == "ctrl+y"); err != nil {
+				m.addToast("Error: " + err.Error())
+			}
+			return m, nil
+		}
+		if m.controlRequesterID == "" {
+			return m, nil
+		}
+		if key == "ctrl+y" {
+			m.currentRoom.GrantControl(m.clientID)
+		} else {
+			m.currentRoom.DenyControl(m.clientID)
+		}
+		m.controlRequester, m.controlRequesterID = "", ""
+		return m, nil
+	case "alt+p":
+		if !m.isInterviewHost() {
+			return m, nil
+		}
+		m.inputMode = ModePinProblem
+		m.cmdInput.Reset()
+		m.cmdInput.Placeholder = "Problem statement, or @file.md to load one..."
+		m.cmdInput.Focus()
+		return m, textinput.Blink
+	case "alt+s":
+		if !m.isInterviewHost() {
+			return m, nil
+		}
+		m.inputMode = ModeTimer
+		m.cmdInput.Reset()
+		m.cmdInput.Placeholder = "Interview length in minutes..."
+		m.cmdInput.Focus()
+		return m, textinput.Blink
+	case "alt+e":
+		if !m.isInterviewHost() {
+			return m, nil
+		}
+		if _, err := m.currentRoom.EndInterview(m.clientID); err != nil {
+			m.addToast("Error: " + err.Error())
+		}
+		return m, nil
+	case "alt+n":
+		return m.skipTurn()
+	case "alt+up", "alt+down":
+		return m.moveInQueue(key)
+	case "alt+i":
+		if !m.isMob() {
+			return m, nil
+		}
+		if !m.isHost {
+			m.addToast("Only the host can change the rotation")
+			return m, nil
+		}
+		m.inputMode = ModeRotation
+		m.cmdInput.Reset()
+		m.cmdInput.Placeholder = "Minutes per turn..."
+		m.cmdInput.Focus()
+		return m, textinput.Blink
+	case "alt+u":
+		return m.openPeople()
+	case "alt+c":
+		return m.toggleRecording()
+	case "alt+h", "pgup":
+		if m.terminal == nil {
+			return m, nil
+		}
+		if key == "pgup" {
+			m.scrollBy(m.terminalPage())
+		} else {
+			m.scrollBy(0)
+		}
+		return m, nil
+	case "alt+m":
+		return m.toggleMyShell()
+	case "alt+v":
+		return m.openRoster()
 	case "ctrl+l":
 		m.cleanup()
 		return m, gotoScreen(ScreenLaunch)
 	}
 
-	if m.terminal != nil {
+	if m.peekClientID != "" {
+		if !m.hasToast("Peeking is read-only (alt+v to go back)") {
+			m.addToast("Peeking is read-only (alt+v to go back)")
+		}
+		return m, nil
+	}
+
+	if m.terminal != nil && m.currentRoom != nil {
 		var data []byte
 		switch key {
 		case "enter":
@@ -453,13 +814,19 @@ func (m *Model) handleRoomKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		if len(data) > 0 {
-			m.terminal.Write(data)
+			if err := m.currentRoom.WriteTerminal(m.clientID, m.tabID, data); err != nil {
+				if (errors.Is(err, room.ErrNotDriver) || errors.Is(err, room.ErrMuted)) && !m.hasToast(err.Error()) {
+					m.addToast(err.Error())
+				}
+				return m, nil
+			}
 
 			// broadcast typing event to other users - debouncing it here as well
-			if m.currentRoom != nil && time.Since(m.typingTime) > 500*time.Millisecond {
-				m.currentRoom.BroadcastEvent(room.RoomEvent{
-					Type:     "typing",
-					Username: m.username,
+			// typing in a private shell isn't news to the room
+			if m.privateTab == "" && time.Since(m.typingTime) > 500*time.Millisecond {
+				m.currentRoom.BroadcastEvent(room.TypingEvent{
+					ClientID: m.clientID,
+					Username: m.name,
 				}, m.clientID)
 				m.typingTime = time.Now()
 			}
@@ -469,8 +836,97 @@ func (m *Model) handleRoomKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// toggleRecording starts or stops recording the room's terminals
+func (m *Model) toggleRecording() (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
+	}
+	if !m.isHost {
+		m.addToast("Only the host can record the session")
+		return m, nil
+	}
+	if err := m.currentRoom.SetRecording(m.clientID, !m.currentRoom.Recording()); err != nil {
+		m.addToast("Error: " + err.Error())
+	}
+	return m, nil
+}
+
+// openTab starts a new shell in the room and switches to it
+func (m *Model) openTab() (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
+	}
+	if !m.canWrite() {
+		m.addToast("Observers can't open terminals")
+		return m, nil
+	}
+	w, h := m.terminalSize()
+	tab, err := m.currentRoom.OpenTab(m.clientID, "", w, h)
+	if err != nil {
+		m.addToast("Error: " + err.Error())
+		return m, nil
+	}
+	m.viewTab(tab)
+	return m, m.waitForTerminalUpdate()
+}
+
+// closeTab kills the shell we're viewing; TabEvent moves everyone off it
+func (m *Model) closeTab() (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
+	}
+	if !m.canWrite() {
+		m.addToast("Observers can't close terminals")
+		return m, nil
+	}
+	if err := m.currentRoom.CloseTab(m.clientID, m.tabID); err != nil {
+		m.addToast("Error: " + err.Error())
+	}
+	return m, nil
+}
+
+// toggleControl releases the keyboard if we're driving, otherwise asks for it
+func (m *Model) toggleControl() (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
+	}
+	if !m.canWrite() {
+		m.addToast("Observers can't take the keyboard")
+		return m, nil
+	}
+	if d := m.currentRoom.Driver(); d != nil && d.ID == m.clientID {
+		m.currentRoom.ReleaseControl(m.clientID)
+		return m, nil
+	}
+	if !m.currentRoom.RequestControl(m.clientID) {
+		m.addToast("Asked for the keyboard...")
+	}
+	return m, nil
+}
+
+// mintInvite creates a single-use invite token for a protected room (host only)
+func (m *Model) mintInvite() (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil || !m.isHost {
+		m.addToast("Only the host can create invite tokens")
+		return m, nil
+	}
+	if !m.currentRoom.HasSecret() {
+		m.addToast("Room has no password - the code alone lets people in")
+		return m, nil
+	}
+	m.inviteToken, m.inviteExpires = m.currentRoom.MintInvite(room.DefaultInviteTTL)
+	m.addToast("Invite token created (single use)")
+	return m, nil
+}
+
 func (m *Model) submitInput() (tea.Model, tea.Cmd) {
 	text := m.cmdInput.Value()
+	// the reason is optional
+	if m.inputMode == ModeModReason {
+		m.inputMode = ModeNormal
+		m.cmdInput.Reset()
+		return m.applyModeration(strings.TrimSpace(text))
+	}
 	if text == "" {
 		m.inputMode = ModeNormal
 		return m, nil
@@ -480,6 +936,38 @@ func (m *Model) submitInput() (tea.Model, tea.Cmd) {
 	m.inputMode = ModeNormal
 	m.cmdInput.Reset()
 
+	// chat is open to everyone, observers included
+	if mode == ModeChat {
+		if m.currentRoom == nil {
+			return m, nil
+		}
+		if err := m.currentRoom.PostChat(m.clientID, text); err != nil {
+			m.addToast("Error: " + err.Error())
+		}
+		return m, nil
+	}
+
+	if mode == ModePinProblem || mode == ModeTimer {
+		return m.submitInterviewInput(mode, text)
+	}
+	if mode == ModeRotation {
+		return m.setRotation(text)
+	}
+
+	// role may have changed while the prompt was open
+	if !m.canWrite() {
+		m.addToast("Error: " + room.ErrReadOnly.Error())
+		return m, nil
+	}
+	m.currentRoom.Touch()
+
+	if mode == ModeRenameTab {
+		if err := m.currentRoom.RenameTab(m.clientID, m.tabID, text); err != nil {
+			m.addToast("Error: " + err.Error())
+		}
+		return m, nil
+	}
+
 	if mode == ModeAI {
 		m.aiLoading = true
 		spinnerCmd := func() tea.Msg { return m.aiSpinner.Tick() }
@@ -488,6 +976,7 @@ func (m *Model) submitInput() (tea.Model, tea.Cmd) {
 
 	if mode == ModeSandbox {
 		m.addToast(fmt.Sprintf("Running: %s", truncate(text, 30)))
+		m.currentRoom.RecordCommand(m.clientID, text)
 		return m, m.execSandboxCmd(text)
 	}
 
@@ -495,6 +984,8 @@ func (m *Model) submitInput() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) sendAIMessage(text string) tea.Cmd {
+	// the worker keys history by the UUID; codes get reused
+	key := m.currentRoom.UUID
 	return func() tea.Msg {
 		if m.aiClient == nil {
 			return ErrorMsg{fmt.Errorf("AI client not configured")}
@@ -503,7 +994,7 @@ func (m *Model) sendAIMessage(text string) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		resp, err := m.aiClient.SendMessage(ctx, m.roomID, text, m.username)
+		resp, err := m.aiClient.SendMessage(ctx, key, text, m.name)
 		if err != nil {
 			return ErrorMsg{err}
 		}
@@ -522,6 +1013,7 @@ func (m *Model) sendAIMessage(text string) tea.Cmd {
 }
 
 func (m *Model) execSandboxCmd(cmd string) tea.Cmd {
+	key := m.currentRoom.UUID
 	return func() tea.Msg {
 		if m.aiClient == nil {
 			return ErrorMsg{fmt.Errorf("AI client not configured")}
@@ -530,7 +1022,7 @@ func (m *Model) execSandboxCmd(cmd string) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		resp, err := m.aiClient.ExecCommand(ctx, m.roomID, cmd)
+		resp, err := m.aiClient.ExecCommand(ctx, key, cmd)
 		if err != nil {
 			return ErrorMsg{err}
 		}
@@ -546,16 +1038,51 @@ func (m *Model) execSandboxCmd(cmd string) tea.Cmd {
 
 func (m *Model) gotoScreen(s Screen) (tea.Model, tea.Cmd) {
 	m.screen = s
+	if s != ScreenLaunch {
+		m.notice = ""
+	}
 	m.inputMode = ModeNormal
 	if s == ScreenCreate {
 		m.input.Reset()
 		m.input.Placeholder = "Room description..."
 		m.input.Focus()
+		m.secretInput.Reset()
+		m.secretInput.Placeholder = "Password (optional)"
+		m.secretInput.Blur()
+		m.tagsInput.Reset()
+		m.tagsInput.Placeholder = "Tags, e.g. go, beginner (optional)"
+		m.tagsInput.Blur()
+		m.isPublic = false
+		m.approval = false
+		m.createFocus = fieldDescription
+		m.templateIdx = 0
+		m.modeIdx = 0
+		return m, textinput.Blink
+	}
+	if s == ScreenJoinAuth {
+		m.secretInput.Reset()
+		m.secretInput.Placeholder = "Password or invite token..."
+		m.secretInput.Focus()
+		return m, textinput.Blink
+	}
+	if s == ScreenLobby {
+		m.input.Reset()
+		m.input.Placeholder = "Filter by description, host or tag..."
+		m.input.Focus()
+		m.lobbyIdx = 0
+		m.refreshLobby()
+		return m, textinput.Blink
+	}
+	if s == ScreenReplays {
+		m.input.Reset()
+		m.input.Placeholder = "Filter by room code, description or tab..."
+		m.input.Focus()
+		m.refreshReplays()
 		return m, textinput.Blink
 	}
 	if s == ScreenJoin {
 		m.input.Reset()
-		m.input.Placeholder = "Room ID..."
+		m.input.Placeholder = "brave-otter-42"
 		m.input.Focus()
 		return m, textinput.Blink
 	}
@@ -564,59 +1091,132 @@ func (m *Model) gotoScreen(s Screen) (tea.Model, tea.Cmd) {
 
 func (m *Model) createRoom() tea.Msg {
 	desc := strings.TrimSpace(m.input.Value())
-	r, err := m.roomManager.CreateRoom(m.username, desc)
+	opts := room.RoomOptions{
+		Description: desc,
+		Secret:      m.secretInput.Value(),
+		Mode:        room.Modes[m.modeIdx],
+		Public:      m.isPublic,
+		Tags:        room.ParseTags(m.tagsInput.Value()),
+		Approval:    m.approval,
+	}
+	if m.templateIdx > 0 {
+		opts.Template = m.roomManager.Templates()[m.templateIdx-1].Name
+	}
+	r, err := m.roomManager.CreateRoom(m.username, opts)
 	if err != nil {
 		return ErrorMsg{err}
 	}
-	m.registerAsClient(r, true)
+	m.registerAsClient(r, room.RoleHost)
 
 	return RoomCreatedMsg{RoomID: r.ID, Room: r}
 }
 
 func (m *Model) joinRoom() tea.Msg {
-	id := strings.TrimSpace(m.input.Value())
-	r, err := m.roomManager.GetRoom(id)
+	return m.openRoom(strings.TrimSpace(m.input.Value()), "")
+}
+
+func (m *Model) joinRoomWithCredential() tea.Msg {
+	return m.openRoom(m.pendingRoomID, strings.TrimSpace(m.secretInput.Value()))
+}
+
+func (m *Model) openRoom(id, credential string) tea.Msg {
+	// banned people aren't even asked for the password
+	r, err := m.roomManager.OpenRoom(id, credential, m.username, m.identity, m.addr)
+	if errors.Is(err, room.ErrCredentialsRequired) {
+		return CredentialsRequiredMsg{RoomID: id}
+	}
 	if err != nil {
 		return ErrorMsg{err}
 	}
-	m.registerAsClient(r, false)
+	role := room.RoleDriver
+	if m.joinObserver {
+		role = room.RoleObserver
+	}
+	// coming back after a dropped connection: take our old seat
+	if clientID, seatRole, ok := m.roomManager.Resume(r.ID, m.identity); ok {
+		m.clientID = clientID
+		role = seatRole
+	} else if r.NeedsApproval(m.identity) {
+		return KnockingMsg{Room: r, Knock: r.Knock(m.username, m.identity)}
+	}
+	m.registerAsClient(r, role)
 
-	return RoomJoinedMsg{RoomID: id, Room: r}
+	return RoomJoinedMsg{RoomID: r.ID, Room: r}
 }
 
-func (m *Model) registerAsClient(r *room.Room, isHost bool) {
-	m.eventChan = make(chan room.RoomEvent, 10)
+func (m *Model) registerAsClient(r *room.Room, role room.Role) {
+	m.isHost = role == room.RoleHost
 
 	client := &room.Client{
 		ID:       m.clientID,
 		Username: m.username,
-		IsHost:   isHost,
-		Events:   m.eventChan,
+		Identity: m.identity,
+		Role:     role,
 	}
 	r.AddClient(client)
+	m.isHost = client.Role == room.RoleHost // a room with no host hands it to us
+	m.name = client.Name
+	m.eventNotify = client.Notify()
 }
 
-func (m *Model) getUserList() []string {
+func (m *Model) getUserList() []participant {
 	if m.currentRoom == nil {
-		return []string{m.username}
+		return []participant{{ID: m.clientID, Name: m.name}}
+	}
+
+	tabNames := make(map[string]string)
+	tabs := m.currentRoom.Tabs()
+	for _, t := range tabs {
+		tabNames[t.ID] = t.Name
 	}
 
 	clients := m.currentRoom.GetClients()
-	users := make([]string, 0, len(clients))
+	users := make([]participant, 0, len(clients))
 	for _, c := range clients {
-		name := c.Username
-		if c.IsHost {
-			name += " (host)"
+		detail := " (" + c.Role.String() + ")"
+		// only worth saying where people are once there's a choice
+		if len(tabs) > 1 && tabNames[c.Viewing] != "" {
+			detail += " → " + tabNames[c.Viewing]
+		} else if c.Viewing != "" && tabNames[c.Viewing] == "" && m.isClassroom() {
+			detail += " → private shell"
 		}
-		if c.Username == m.username {
-			name += " (you)"
+		if m.currentRoom.Muted(c.ID) {
+			detail += " (muted)"
 		}
-		users = append(users, name)
+		if c.ID == m.clientID {
+			detail += " (you)"
+		}
+		users = append(users, participant{ID: c.ID, Name: c.Name, Color: c.Color, Detail: detail})
 	}
 	return users
 }
 
+// canWrite reports whether this client may type into the terminal or use AI/sandbox
+func (m *Model) canWrite() bool {
+	if m.currentRoom == nil {
+		return false
+	}
+	return m.currentRoom.ClientRole(m.clientID).CanWrite()
+}
+
+// Disconnect is called once the SSH session has ended. Unlike leaving with
+// ctrl+l, the room holds our seat for the reconnect grace period if we
+// logged in with a key.
+func (m *Model) Disconnect() {
+	m.cancelKnock()
+	if m.terminal != nil && m.termUpdateCh != nil {
+		m.terminal.Unsubscribe(m.termUpdateCh)
+		m.termUpdateCh = nil
+	}
+
+	if m.currentRoom != nil && m.roomID != "" {
+		m.roomManager.DisconnectClient(m.roomID, m.clientID, m.identity)
+		m.currentRoom = nil
+	}
+}
+
 func (m *Model) cleanup() {
+	m.cancelKnock()
 	if m.terminal != nil && m.termUpdateCh != nil {
 		m.terminal.Unsubscribe(m.termUpdateCh)
 		m.termUpdateCh = nil
@@ -628,71 +1228,116 @@ func (m *Model) cleanup() {
 	}
 
 	m.terminal = nil
+	m.scrolling, m.scrollOffset = false, 0
+	m.tabID = ""
 	m.termContent = ""
+	m.eventNotify = nil
+	m.aiMessages = nil
+	m.chatMessages = nil
+	m.chatUnread = 0
+	m.interviewDeadline, m.summaryPath = time.Time{}, ""
+	m.privateTab, m.peekClientID, m.rosterOpen = "", "", false
+	m.peopleOpen, m.pendingMod = false, pendingModeration{}
+	if m.sidebarTab == TabProblem {
+		m.sidebarTab = TabAI
+	}
 	m.roomID = ""
-	m.users = []string{}
+	m.pendingRoomID = ""
+	m.isHost = false
+	m.inviteToken = ""
+	m.controlRequester, m.controlRequesterID = "", ""
+	m.roomExpires, m.expiryReason = time.Time{}, ""
+	m.users = []participant{}
+	m.name = m.username
 }
 
 func (m *Model) startTerminal() tea.Cmd {
 	return func() tea.Msg {
-		if m.currentRoom != nil && m.currentRoom.Terminal != nil {
-			m.terminal = m.currentRoom.Terminal
-			m.termUpdateCh = m.terminal.Subscribe()
-			m.termContent = m.terminal.Render()
-			return terminalUpdateMsg{} // start listening for updates
+		if m.currentRoom == nil {
+			return nil
 		}
 
-		_, terminalW, _, mainH := m.roomLayout()
-		termH := mainH - 4 // account for header and padding
-
-		if terminalW < 40 {
-			terminalW = 80
-		}
-		if termH < 10 {
-			termH = 24
+		// joins the room's first tab, starting its shell if nobody has yet
+		terminalW, termH := m.terminalSize()
+		tab, err := m.currentRoom.EnsureTab(terminalW, termH)
+		if err != nil {
+			return ErrorMsg{err}
 		}
 
-		m.terminal = terminal.New(terminalW, termH)
+		m.viewTab(tab)
+		return terminalUpdateMsg{} // start listening for updates
+	}
+}
 
-		if err := m.terminal.Start(); err != nil {
-			return ErrorMsg{err}
-		}
+// terminalSize returns the size new terminals should start at
+func (m *Model) terminalSize() (w, h int) {
+	_, w, _, mainH := m.roomLayout()
+	h = mainH - 4 // account for header and padding
 
-		if m.currentRoom != nil {
-			m.currentRoom.Terminal = m.terminal
-		}
+	if w < 40 {
+		w = 80
+	}
+	if h < 10 {
+		h = 24
+	}
+	return w, h
+}
 
-		// Subscribe to terminal updates (per-client channel)
-		m.termUpdateCh = m.terminal.Subscribe()
-		m.termContent = m.terminal.Render()
-		return terminalUpdateMsg{} // start listening for updates
+// viewTab points this client at a tab, moving its terminal subscription
+// over and telling the room which tab we're watching.
+func (m *Model) viewTab(tab room.Tab) {
+	if m.terminal != nil && m.termUpdateCh != nil {
+		m.terminal.Unsubscribe(m.termUpdateCh)
 	}
+
+	m.tabID = tab.ID
+	m.privateTab, m.peekClientID = "", ""
+	m.terminal = tab.Terminal
+	// Subscribe to terminal updates (per-client channel)
+	m.termUpdateCh = m.terminal.Subscribe()
+	m.scrolling, m.scrollOffset = false, 0
+	m.termContent = m.terminal.Render(m.styles.profile)
+	m.currentRoom.SetViewing(m.clientID, tab.ID)
 }
 
-// listens for terminal updates via per-client subscription
-func (m *Model) waitForTerminalUpdate() tea.Cmd {
-	if m.terminal == nil || m.termUpdateCh == nil {
-		return nil
+// switchTab moves to the tab at index i in the tab strip
+func (m *Model) switchTab(i int) (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
 	}
-	return func() tea.Msg {
-		_, ok := <-m.termUpdateCh
-		if !ok {
-			return nil // Channel closed
+	tabs := m.currentRoom.Tabs()
+	if i < 0 || i >= len(tabs) || tabs[i].ID == m.tabID {
+		return m, nil
+	}
+	m.viewTab(tabs[i])
+	return m, m.waitForTerminalUpdate()
+}
+
+// currentTabIndex returns where our tab sits in the tab strip, or -1
+func (m *Model) currentTabIndex() int {
+	if m.currentRoom == nil {
+		return -1
+	}
+	for i, t := range m.currentRoom.Tabs() {
+		if t.ID == m.tabID {
+			return i
 		}
-		return terminalUpdateMsg{}
 	}
+	return -1
 }
 
-func (m *Model) listenForRoomEvents() tea.Cmd {
-	if m.eventChan == nil {
+// listens for terminal updates via per-client subscription
+func (m *Model) waitForTerminalUpdate() tea.Cmd {
+	if m.terminal == nil || m.termUpdateCh == nil {
 		return nil
 	}
+	ch := m.termUpdateCh
 	return func() tea.Msg {
-		event, ok := <-m.eventChan
+		_, ok := <-ch
 		if !ok {
-			return nil
+			return nil // Channel closed
 		}
-		return roomEventMsg{Event: event}
+		return terminalUpdateMsg{}
 	}
 }
 
@@ -706,6 +1351,15 @@ func (m *Model) addToast(text string) {
 	}
 }
 
+func (m *Model) hasToast(text string) bool {
+	for _, t := range m.toasts {
+		if t.text == text {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Model) expireToasts() {
 	now := time.Now()
 	var active []toast
@@ -728,27 +1382,71 @@ func (m *Model) View() string {
 		return m.viewCreate()
 	case ScreenJoin:
 		return m.viewJoin()
+	case ScreenJoinAuth:
+		return m.viewJoinAuth()
 	case ScreenRoomCreated:
 		return m.viewRoomCreated()
 	case ScreenRoom:
 		return m.viewRoom()
+	case ScreenLobby:
+		return m.viewLobby()
+	case ScreenWaiting:
+		return m.viewWaiting()
+	case ScreenReplays:
+		return m.viewReplays()
+	case ScreenReplay:
+		return m.viewReplay()
 	}
 	return ""
 }
 
 // Helpers
 
-func gotoScreen(s Screen) tea.Cmd {
-	return func() tea.Msg { return GotoScreenMsg{s} }
+// cycleCreateFocus moves between the create screen's fields, skipping the
+// template picker when no templates are configured
+func (m *Model) cycleCreateFocus(step int) tea.Cmd {
+	n := int(fieldTemplate)
+	if len(m.roomManager.Templates()) > 0 {
+		n++
+	}
+	m.createFocus = createField((int(m.createFocus) + step + n) % n)
+
+	m.input.Blur()
+	m.secretInput.Blur()
+	m.tagsInput.Blur()
+	switch m.createFocus {
+	case fieldDescription:
+		return m.input.Focus()
+	case fieldSecret:
+		return m.secretInput.Focus()
+	case fieldTags:
+		return m.tagsInput.Focus()
+	}
+	return nil
 }
 
-func removeUser(users []string, name string) []string {
-	for i, u := range users {
-		if u == name {
-			return append(users[:i], users[i+1:]...)
+// updateFocusedInput forwards msg to whichever text input has focus on the current screen
+func (m *Model) updateFocusedInput(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	if m.screen == ScreenCreate {
+		switch m.createFocus {
+		case fieldPublic, fieldApproval, fieldMode, fieldTemplate:
+			return nil
+		case fieldTags:
+			m.tagsInput, cmd = m.tagsInput.Update(msg)
+			return cmd
 		}
 	}
-	return users
+	if m.screen == ScreenJoinAuth || (m.screen == ScreenCreate && m.createFocus == fieldSecret) {
+		m.secretInput, cmd = m.secretInput.Update(msg)
+		return cmd
+	}
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+func gotoScreen(s Screen) tea.Cmd {
+	return func() tea.Msg { return GotoScreenMsg{s} }
 }
 
 func truncate(s string, max int) string {
@@ -767,15 +1465,51 @@ func (m *Model) syncAIViewportContent() {
 	m.lastPromptOffset = promptOffset
 }
 
+// rebuilds the chat viewport, keeping it pinned to the bottom if it was there
+func (m *Model) syncChatViewportContent() {
+	atBottom := m.chatViewport.AtBottom()
+	m.chatViewport.SetContent(m.buildChatContent(m.chatViewport.Width))
+	if atBottom {
+		m.chatViewport.GotoBottom()
+	}
+}
+
+// setSidebarTab switches the right-hand sidebar, clearing unread chat when it's shown
+func (m *Model) setSidebarTab(tab SidebarTab) {
+	m.sidebarTab = tab
+	if tab == TabChat {
+		m.chatUnread = 0
+	}
+}
+
+// activeSidebarViewport returns the viewport for the visible sidebar tab
+func (m *Model) activeSidebarViewport() *viewport.Model {
+	switch m.sidebarTab {
+	case TabChat:
+		return &m.chatViewport
+	case TabProblem:
+		return &m.problemViewport
+	}
+	return &m.aiViewport
+}
+
+// sidebarTabs lists the right-hand sidebar's tabs; interview rooms get the problem pane
+func (m *Model) sidebarTabs() []SidebarTab {
+	if m.currentRoom != nil && m.currentRoom.Mode == room.ModeInterview {
+		return []SidebarTab{TabAI, TabChat, TabProblem}
+	}
+	return []SidebarTab{TabAI, TabChat}
+}
+
 // scrolls the AI viewport to show the last user prompt
 func (m *Model) scrollToLastPrompt() {
 	m.aiViewport.SetYOffset(m.lastPromptOffset)
 }
 
-// returns AI messages from the current room, or empty slice if no room.
+// returns the AI conversation for the current room, or nil if no room.
 func (m *Model) getAIMessages() []AIMessage {
 	if m.currentRoom == nil {
 		return nil
 	}
-	return m.currentRoom.GetAIMessages()
+	return m.aiMessages
 }
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaypopat/duet/internal/room"
+)
+
+// pendingModeration is a kick or ban waiting for the host to give a reason
+type pendingModeration struct {
+	action   room.ModerationAction
+	byKey    bool // ban the key fingerprint rather than the username
+	clientID string
+}
+
+// openPeople shows the host everyone else in the room to moderate
+func (m *Model) openPeople() (tea.Model, tea.Cmd) {
+	if m.currentRoom == nil {
+		return m, nil
+	}
+	if !m.isHost {
+		m.addToast("Only the host can manage people")
+		return m, nil
+	}
+	m.peopleOpen = true
+	m.peopleIdx = 0
+	return m, nil
+}
+
+// moderatable lists the other participants in the room
+func (m *Model) moderatable() []*room.Client {
+	var people []*room.Client
+	for _, c := range m.currentRoom.GetClients() {
+		if c.ID != m.clientID {
+			people = append(people, c)
+		}
+	}
+	return people
+}
+
+func (m *Model) handlePeopleKey(key string) (tea.Model, tea.Cmd) {
+	people := m.moderatable()
+	m.peopleIdx = min(m.peopleIdx, max(0, len(people)-1))
+
+	switch key {
+	case "up", "k":
+		m.peopleIdx = max(0, m.peopleIdx-1)
+		return m, nil
+	case "down", "j":
+		m.peopleIdx = min(max(0, len(people)-1), m.peopleIdx+1)
+		return m, nil
+	case "esc", "alt+u":
+		m.peopleOpen = false
+		return m, nil
+	}
+	if len(people) == 0 {
+		return m, nil
+	}
+	target := people[m.peopleIdx]
+
+	switch key {
+	case "m":
+		muted := !m.currentRoom.Muted(target.ID)
+		if err := m.currentRoom.SetMuted(m.clientID, target.ID, muted); err != nil {
+			m.addToast("Error: " + err.Error())
+		}
+		return m, nil
+	case "h":
+		if err := m.currentRoom.TransferHost(m.clientID, target.ID); err != nil {
+			m.addToast("Error: " + err.Error())
+		}
+		return m, nil
+	case "x":
+		m.pendingMod = pendingModeration{action: room.ModKicked, clientID: target.ID}
+	case "b", "B":
+		m.pendingMod = pendingModeration{action: room.ModBanned, byKey: key == "B", clientID: target.ID}
+	default:
+		return m, nil
+	}
+
+	m.inputMode = ModeModReason
+	m.cmdInput.Reset()
+	m.cmdInput.Placeholder = fmt.Sprintf("Reason for removing %s (optional)...", target.Name)
+	m.cmdInput.Focus()
+	return m, textinput.Blink
+}
+
+// applyModeration carries out the pending kick or ban once we have a reason
+func (m *Model) applyModeration(reason string) (tea.Model, tea.Cmd) {
+	p := m.pendingMod
+	m.pendingMod = pendingModeration{}
+	if m.currentRoom == nil || p.clientID == "" {
+		return m, nil
+	}
+
+	var err error
+	if p.action == room.ModBanned {
+		err = m.currentRoom.Ban(m.clientID, p.clientID, p.byKey, reason)
+	} else {
+		err = m.currentRoom.Kick(m.clientID, p.clientID, reason)
+	}
+	if err != nil {
+		m.addToast("Error: " + err.Error())
+	}
+	return m, nil
+}
+
+// handleModerationEvent tells everyone what the host did, and sends us
+// back to the launch screen if it was done to us. Reports whether we left.
+func (m *Model) handleModerationEvent(ev room.ModerationEvent) bool {
+	m.users = m.getUserList()
+
+	var verb string
+	switch ev.Action {
+	case room.ModMuted:
+		if ev.ClientID == m.clientID {
+			m.addToast("The host muted you")
+		} else {
+			m.addToast(fmt.Sprintf("%s was muted", ev.Username))
+		}
+		return false
+	case room.ModUnmuted:
+		if ev.ClientID == m.clientID {
+			m.addToast("The host unmuted you")
+		} else {
+			m.addToast(fmt.Sprintf("%s was unmuted", ev.Username))
+		}
+		return false
+	case room.ModKicked:
+		verb = "removed"
+	case room.ModBanned:
+		verb = "banned"
+	}
+
+	if ev.ClientID != m.clientID {
+		m.addToast(fmt.Sprintf("%s was %s", ev.Username, verb))
+		return false
+	}
+
+	notice := fmt.Sprintf("You were %s from %s", verb, m.roomID)
+	if ev.Reason != "" {
+		notice += ": " + ev.Reason
+	}
+	m.cleanup()
+	m.screen = ScreenLaunch
+	m.notice = notice
+	return true
+}
+
+// renderPeople draws the host's participant panel in place of the terminal
+func (m *Model) renderPeople(w, h int) string {
+	people := m.moderatable()
+
+	var b strings.Builder
+	b.WriteString(m.styles.titleStyle.Render(fmt.Sprintf("people (%d)", len(people))) + "\n\n")
+	if len(people) == 0 {
+		b.WriteString(m.styles.dimStyle.Render("Nobody else is here.") + "\n")
+	}
+	for i, c := range people {
+		row := fmt.Sprintf("%s (%s)", c.Name, c.Role)
+		if m.currentRoom.Muted(c.ID) {
+			row += " (muted)"
+		}
+		if i == m.peopleIdx {
+			b.WriteString(m.styles.accentStyle.Bold(true).Render("▸ "+truncate(row, w-8)) + "\n")
+		} else {
+			b.WriteString(m.styles.textStyle.Render("  "+truncate(row, w-8)) + "\n")
+		}
+	}
+	b.WriteString("\n" + m.styles.dimStyle.Render("m mute/unmute • h make host • x kick • b ban name • B ban key • esc close"))
+
+	return m.styles.terminalStyle.Width(w).Height(h).Render(b.String())
+}
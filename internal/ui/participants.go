@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"hash/fnv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// participant is one person in the room as the sidebar lists them
+type participant struct {
+	ID     string // client ID; display names can change between joins
+	Name   string
+	Color  int
+	Detail string // role, whereabouts and flags shown after the name
+}
+
+// nameStyle colors a display name the way the roster does. People who have
+// left get a color picked from their name so they stay consistent.
+func (m *Model) nameStyle(name string) lipgloss.Style {
+	if m.currentRoom != nil {
+		if c := m.currentRoom.ClientByName(name); c != nil {
+			return m.styles.people[c.Color%len(m.styles.people)]
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return m.styles.people[h.Sum32()%uint32(len(m.styles.people))]
+}
@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jaypopat/duet/internal/terminal"
+)
+
+const (
+	replayFrame    = time.Second / 30
+	replayIdle     = 2 * time.Second // longest pause with idle compression on
+	replaySeekStep = 5 * time.Second
+)
+
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16}
+
+// replayPlayer plays a recording back through a shell-less terminal
+type replayPlayer struct {
+	name     string
+	raw      *terminal.Cast
+	cast     *terminal.Cast // raw, or with idle time compressed
+	compress bool
+	term     *terminal.Terminal
+	next     int           // index of the next event to apply
+	pos      time.Duration // where playback is, in cast time
+	speedIdx int           // into replaySpeeds
+	paused   bool
+	ticking  bool // a replayTickMsg is on its way
+	lastTick time.Time
+}
+
+func newReplayPlayer(name string, c *terminal.Cast) *replayPlayer {
+	p := &replayPlayer{
+		name:     name,
+		raw:      c,
+		cast:     c.CompressIdle(replayIdle),
+		compress: true,
+		speedIdx: 2, // 1x
+		lastTick: time.Now(),
+	}
+	p.rewind()
+	return p
+}
+
+// rewind goes back to a blank screen at the start
+func (p *replayPlayer) rewind() {
+	if p.term != nil {
+		p.term.Close()
+	}
+	p.term = terminal.NewReplay(p.cast.Width, p.cast.Height)
+	p.next, p.pos = 0, 0
+}
+
+// seek moves playback to t. Going backwards replays from the start, which
+// the emulator gets through quickly.
+func (p *replayPlayer) seek(t time.Duration) {
+	t = max(0, min(t, p.cast.Duration()))
+	if t < p.pos {
+		p.rewind()
+	}
+	for p.next < len(p.cast.Events) && p.cast.Events[p.next].Time <= t {
+		ev := p.cast.Events[p.next]
+		switch ev.Code {
+		case "o":
+			p.term.Feed([]byte(ev.Data))
+		case "r":
+			if w, h, ok := terminal.ParseSize(ev.Data); ok {
+				p.term.Resize(w, h)
+			}
+		}
+		p.next++
+	}
+	p.pos = t
+}
+
+// tick advances playback by the wall time since the last tick
+func (p *replayPlayer) tick(now time.Time) {
+	elapsed := now.Sub(p.lastTick)
+	p.lastTick = now
+	if p.paused || p.finished() {
+		return
+	}
+	p.seek(p.pos + time.Duration(float64(elapsed)*replaySpeeds[p.speedIdx]))
+}
+
+func (p *replayPlayer) finished() bool {
+	return p.pos >= p.cast.Duration()
+}
+
+// toggleCompress switches idle compression, staying on the same event
+func (p *replayPlayer) toggleCompress() {
+	p.compress = !p.compress
+	p.cast = p.raw
+	if p.compress {
+		p.cast = p.raw.CompressIdle(replayIdle)
+	}
+	if p.next > 0 {
+		p.pos = p.cast.Events[p.next-1].Time
+	} else {
+		p.pos = 0
+	}
+}
+
+// replayTickMsg drives playback; gen ties it to one player so an old
+// tick loop dies when a new recording is opened
+type replayTickMsg struct{ gen int }
+
+func (m *Model) replayTick() tea.Cmd {
+	gen := m.replayGen
+	return tea.Tick(replayFrame, func(time.Time) tea.Msg { return replayTickMsg{gen} })
+}
+
+// resumeReplay restarts the tick loop if playback stopped it, so a paused
+// or finished player doesn't redraw for nothing
+func (m *Model) resumeReplay() tea.Cmd {
+	p := m.replay
+	if p == nil || p.ticking || p.paused || p.finished() {
+		return nil
+	}
+	p.ticking = true
+	p.lastTick = time.Now()
+	return m.replayTick()
+}
+
+// refreshReplays lists the recordings this user was in the room for,
+// filtered by m.input
+func (m *Model) refreshReplays() {
+	m.replayList, m.replayErr = nil, ""
+	m.replayIdx = 0
+	recs, err := m.roomManager.Recordings(m.identity)
+	if err != nil {
+		m.replayErr = err.Error()
+		return
+	}
+	filter := strings.TrimSpace(m.input.Value())
+	for _, r := range recs {
+		if _, ok := fuzzyScore(filter, r.Room+" "+r.Description+" "+r.Name); ok {
+			m.replayList = append(m.replayList, r)
+		}
+	}
+}
+
+func (m *Model) handleReplaysKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "ctrl+p":
+		if m.replayIdx > 0 {
+			m.replayIdx--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.replayIdx < len(m.replayList)-1 {
+			m.replayIdx++
+		}
+		return m, nil
+	case "enter":
+		if m.replayIdx >= len(m.replayList) {
+			return m, nil
+		}
+		return m.openReplay(m.replayList[m.replayIdx].Path, m.replayList[m.replayIdx].Name)
+	case "esc":
+		return m, gotoScreen(ScreenLaunch)
+	}
+
+	before := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != before {
+		m.refreshReplays()
+	}
+	return m, cmd
+}
+
+// openReplay loads a recording and starts playing it
+func (m *Model) openReplay(path, name string) (tea.Model, tea.Cmd) {
+	f, err := os.Open(path)
+	if err != nil {
+		m.replayErr = err.Error()
+		return m, nil
+	}
+	defer f.Close()
+
+	c, err := terminal.ReadCast(f)
+	if err != nil {
+		m.replayErr = err.Error()
+		return m, nil
+	}
+	m.replay = newReplayPlayer(name, c)
+	m.replayGen++
+	m.screen = ScreenReplay
+	return m, m.resumeReplay()
+}
+
+// closeReplay stops playback and goes back to the list
+func (m *Model) closeReplay() {
+	if m.replay != nil {
+		m.replay.term.Close()
+		m.replay = nil
+	}
+	m.replayGen++
+	m.screen = ScreenReplays
+}
+
+func (m *Model) handleReplayKey(key string) (tea.Model, tea.Cmd) {
+	p := m.replay
+	switch key {
+	case " ", "p":
+		if p.finished() {
+			p.rewind()
+			p.paused = false
+		} else {
+			p.paused = !p.paused
+		}
+	case "left", "h":
+		p.seek(p.pos - replaySeekStep)
+	case "right", "l":
+		p.seek(p.pos + replaySeekStep)
+	case "up", "+", "=":
+		p.speedIdx = min(len(replaySpeeds)-1, p.speedIdx+1)
+	case "down", "-":
+		p.speedIdx = max(0, p.speedIdx-1)
+	case "i":
+		p.toggleCompress()
+	case "home", "0":
+		p.rewind()
+	case "end":
+		p.seek(p.cast.Duration())
+	case "esc", "q":
+		m.closeReplay()
+		return m, nil
+	}
+	return m, m.resumeReplay()
+}
+
+func (m *Model) viewReplays() string {
+	title := m.styles.titleStyle.Render("Replays")
+	prompt := m.styles.textStyle.Render("Recordings of rooms you were in:")
+	input := m.styles.inputBoxStyle.Render(m.input.View())
+	help := m.styles.helpStyle.Render("↑/↓ select • enter play • esc back")
+
+	width := min(m.width-4, 90)
+	var rows []string
+	switch {
+	case m.replayErr != "":
+		rows = append(rows, m.styles.errorStyle.Render("▸ "+m.replayErr))
+	case len(m.replayList) == 0 && strings.TrimSpace(m.input.Value()) != "":
+		rows = append(rows, m.styles.dimStyle.Render("No recordings match."))
+	case len(m.replayList) == 0:
+		rows = append(rows, m.styles.dimStyle.Render("No recordings yet."))
+	}
+	visible := max(1, m.height-14)
+	start := max(0, m.replayIdx-visible+1)
+	for i := start; i < len(m.replayList) && i < start+visible; i++ {
+		r := m.replayList[i]
+		row := fmt.Sprintf("%s  %s  (%d KB, %s)", r.Room, r.Name, (r.Size+1023)/1024, r.ModTime.Format("2006-01-02 15:04"))
+		if i == m.replayIdx {
+			rows = append(rows, m.styles.accentStyle.Bold(true).Render("▸ "+truncate(row, width-2)))
+		} else {
+			rows = append(rows, m.styles.textStyle.Render("  "+truncate(row, width-2)))
+		}
+	}
+
+	list := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	content := lipgloss.JoinVertical(lipgloss.Center, title, "", prompt, "", input, "", list, "", help)
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m *Model) viewReplay() string {
+	p := m.replay
+
+	state := "playing"
+	switch {
+	case p.finished():
+		state = "finished"
+	case p.paused:
+		state = "paused"
+	}
+	idle := "idle kept"
+	if p.compress {
+		idle = fmt.Sprintf("idle ≤%s", replayIdle)
+	}
+	status := fmt.Sprintf("%s / %s • %gx • %s • %s",
+		formatClock(p.pos), formatClock(p.cast.Duration()), replaySpeeds[p.speedIdx], state, idle)
+
+	header := m.styles.titleStyle.Render(truncate(p.name, max(10, m.width-lipgloss.Width(status)-8))) +
+		"  " + m.styles.dimStyle.Render(status)
+	screen := m.styles.terminalStyle.Render(p.term.Render(m.styles.profile))
+	help := m.styles.helpStyle.Render("space pause • ←/→ seek 5s • ↑/↓ speed • i idle compression • home restart • esc back")
+
+	content := lipgloss.JoinVertical(lipgloss.Left, header, screen, help)
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
+}
+
+// formatClock shows a duration as m:ss
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
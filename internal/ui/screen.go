@@ -9,8 +9,26 @@ const (
 	ScreenLaunch Screen = iota
 	ScreenCreate
 	ScreenJoin
+	ScreenJoinAuth    // Asks for the room password or an invite token
 	ScreenRoomCreated // Shows room code for copying before entering room
 	ScreenRoom
+	ScreenLobby   // Lists public rooms to browse and join
+	ScreenWaiting // Waiting for the host to let us in
+	ScreenReplays // Lists recordings of rooms the user was in
+	ScreenReplay  // Plays a recording back
+)
+
+// represents which field has focus on the create screen
+type createField int
+
+const (
+	fieldDescription createField = iota
+	fieldSecret
+	fieldTags
+	fieldPublic
+	fieldApproval
+	fieldMode
+	fieldTemplate
 )
 
 // represents the input mode in the room screen
@@ -20,6 +38,21 @@ const (
 	ModeNormal InputMode = iota
 	ModeAI
 	ModeSandbox
+	ModeChat
+	ModeRenameTab
+	ModePinProblem
+	ModeTimer
+	ModeRotation
+	ModeModReason
+)
+
+// represents which tab of the right-hand sidebar is showing
+type SidebarTab int
+
+const (
+	TabAI SidebarTab = iota
+	TabChat
+	TabProblem // interview rooms only
 )
 
 // Navigation messages
@@ -38,6 +71,24 @@ type RoomJoinedMsg struct {
 	Room   *room.Room
 }
 
+// sent when the room exists but needs a password or invite token
+type CredentialsRequiredMsg struct {
+	RoomID string
+}
+
+// sent when the room needs the host's approval and we've knocked
+type KnockingMsg struct {
+	Room  *room.Room
+	Knock *room.Knock
+}
+
+// sent when the host answers our knock, or the room closes first
+type KnockAnsweredMsg struct {
+	Knock    *room.Knock
+	Approved bool
+	Closed   bool
+}
+
 // Toast/notification messages
 
 type ToastMsg struct {
@@ -68,7 +119,14 @@ type tickMsg struct{}
 
 type terminalUpdateMsg struct{}
 
-// Room event message (from event channel)
-type roomEventMsg struct {
-	Event room.RoomEvent
+// removedMsg: the room closed our notify channel, so we're no longer in it
+type removedMsg struct {
+	room     *room.Room
+	clientID string
+}
+
+// Room events read from the room's log since the last batch
+type roomEventsMsg struct {
+	Events []room.RoomEvent
+	Resync bool // we fell behind and missed events; rebuild from room state
 }
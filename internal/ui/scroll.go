@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wheelLines is how far one notch of the mouse wheel scrolls
+const wheelLines = 3
+
+// scrollBy moves our view n lines back into the terminal's history
+// (negative is towards live output). Only we see the difference; everyone
+// else keeps watching live. Scrolling past the bottom goes back to live.
+func (m *Model) scrollBy(n int) {
+	if m.terminal == nil {
+		return
+	}
+	kept, total := m.terminal.Scrollback()
+	if !m.scrolling {
+		m.scrolling = true
+		m.scrollOffset = 0
+		m.scrollTotal = total
+	}
+	if m.scrollOffset+n < 0 {
+		m.stopScrolling()
+		return
+	}
+	m.scrollOffset = min(kept, m.scrollOffset+n)
+	m.refreshTerminal()
+}
+
+// stopScrolling goes back to the live terminal
+func (m *Model) stopScrolling() {
+	m.scrolling = false
+	m.scrollOffset = 0
+	m.refreshTerminal()
+}
+
+// refreshTerminal re-renders the terminal we're viewing, holding our place
+// in history while new output pushes more lines into it.
+func (m *Model) refreshTerminal() {
+	if m.terminal == nil {
+		return
+	}
+	if !m.scrolling {
+		m.termContent = m.terminal.Render(m.styles.profile)
+		return
+	}
+	kept, total := m.terminal.Scrollback()
+	m.scrollOffset = min(kept, m.scrollOffset+int(total-m.scrollTotal))
+	m.scrollTotal = total
+	m.termContent = m.terminal.RenderScrolled(m.scrollOffset, m.styles.profile)
+}
+
+// terminalPage is how far PgUp/PgDn move: a screenful, less a line to keep
+// some context
+func (m *Model) terminalPage() int {
+	_, h := m.terminal.Size()
+	return max(1, h-1)
+}
+
+// handleScrollKey drives scroll mode. Reports false for keys that aren't
+// about scrolling, which take us back to live and then do what they'd
+// normally do.
+func (m *Model) handleScrollKey(key string) bool {
+	switch key {
+	case "up", "k":
+		m.scrollBy(1)
+	case "down", "j":
+		m.scrollBy(-1)
+	case "pgup":
+		m.scrollBy(m.terminalPage())
+	case "pgdown":
+		m.scrollBy(-m.terminalPage())
+	case "home", "g":
+		kept, _ := m.terminal.Scrollback()
+		m.scrollBy(kept)
+	case "end", "G", "esc", "q", "alt+h":
+		m.stopScrolling()
+	default:
+		m.stopScrolling()
+		return false
+	}
+	return true
+}
+
+// syncMouse turns mouse reporting on in the room, where the wheel scrolls
+// the terminal, and off everywhere else so text can be selected natively,
+// e.g. to copy a room code
+func (m *Model) syncMouse() tea.Cmd {
+	want := m.screen == ScreenRoom
+	if m.mouseOn == want {
+		return nil
+	}
+	m.mouseOn = want
+	if want {
+		return tea.EnableMouseCellMotion
+	}
+	return tea.DisableMouse
+}
+
+// handleMouse scrolls the terminal with the wheel
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.screen != ScreenRoom || m.rosterOpen || m.peopleOpen || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollBy(wheelLines)
+	case tea.MouseButtonWheelDown:
+		if m.scrolling {
+			m.scrollBy(-wheelLines)
+		}
+	}
+	return m, nil
+}
+
+// scrollStatus describes where we are in history for the terminal header
+func (m *Model) scrollStatus() string {
+	if m.scrollOffset == 0 {
+		return "  (history: paused • ↑/pgup to scroll, esc for live)"
+	}
+	return fmt.Sprintf("  (history: %d lines up • esc for live)", m.scrollOffset)
+}
@@ -1,6 +1,10 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jaypopat/duet/internal/room"
+	"github.com/muesli/termenv"
+)
 
 const (
 	colorAccent  = lipgloss.Color("6") // Cyan (ANSI 6)
@@ -11,6 +15,19 @@ const (
 	colorSuccess = lipgloss.Color("2") // Green (ANSI 2)
 )
 
+// participantColors tell people apart in the roster and chat, indexed by
+// room.Client.Color
+var participantColors = [room.ParticipantColors]lipgloss.Color{
+	"6",  // Cyan
+	"3",  // Yellow
+	"5",  // Magenta
+	"2",  // Green
+	"4",  // Blue
+	"1",  // Red
+	"14", // Bright cyan
+	"13", // Bright magenta
+}
+
 // Styles struct holds renderer-aware styles for a session
 type Styles struct {
 	baseStyle        lipgloss.Style
@@ -30,6 +47,11 @@ type Styles struct {
 	logoStyle        lipgloss.Style
 	inputBoxStyle    lipgloss.Style
 	bottomBarStyle   lipgloss.Style
+	people           [room.ParticipantColors]lipgloss.Style
+
+	// what the session's terminal can show; the shared terminal is
+	// downsampled to it
+	profile termenv.Profile
 }
 
 // NewStyles creates renderer-aware styles for the given renderer
@@ -40,7 +62,14 @@ func NewStyles(renderer *lipgloss.Renderer) *Styles {
 
 	baseStyle := renderer.NewStyle()
 
+	var people [room.ParticipantColors]lipgloss.Style
+	for i, c := range participantColors {
+		people[i] = baseStyle.Foreground(c)
+	}
+
 	return &Styles{
+		profile:   renderer.ColorProfile(),
+		people:    people,
 		baseStyle: baseStyle,
 		titleStyle: baseStyle.
 			Foreground(colorAccent).
@@ -3,26 +3,32 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jaypopat/duet/internal/room"
 	"github.com/muesli/reflow/wordwrap"
 )
 
 func (m *Model) viewLaunch() string {
 	logo := m.styles.logoStyle.Render(asciiLogo)
 
-	createBtn := m.styles.buttonStyle.Render("Create Room  (c)")
-	joinBtn := m.styles.buttonStyle.Render("Join Room    (J)")
-
-	if m.selected == 0 {
-		createBtn = m.styles.buttonActive.Render("Create Room  (c)")
-	} else {
-		joinBtn = m.styles.buttonActive.Render("Join Room    (J)")
+	labels := []string{"Create Room  (c)", "Join Room    (J)", "Browse Rooms (l)", "Replays      (r)"}
+	btns := make([]string, len(labels))
+	for i, label := range labels {
+		if i == m.selected {
+			btns[i] = m.styles.buttonActive.Render(label)
+		} else {
+			btns[i] = m.styles.buttonStyle.Render(label)
+		}
 	}
 
-	buttons := lipgloss.JoinVertical(lipgloss.Center, createBtn, joinBtn)
+	buttons := lipgloss.JoinVertical(lipgloss.Center, btns...)
 	help := m.styles.helpStyle.Render("↑/↓ select • enter confirm • q quit")
 	content := lipgloss.JoinVertical(lipgloss.Center, logo, buttons, help)
+	if m.notice != "" {
+		content = lipgloss.JoinVertical(lipgloss.Center, content, "", m.styles.errorStyle.Render(m.notice))
+	}
 
 	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
 }
@@ -31,22 +37,67 @@ func (m *Model) viewCreate() string {
 	title := m.styles.titleStyle.Render("Create Room")
 	prompt := m.styles.textStyle.Render("Enter a description for your room:")
 	input := m.styles.inputBoxStyle.Render(m.input.View())
-	help := m.styles.helpStyle.Render("enter create • esc back")
+	secretPrompt := m.styles.dimStyle.Render("Optional password - leave blank for an open room:")
+	secret := m.styles.inputBoxStyle.Render(m.secretInput.View())
+	help := m.styles.helpStyle.Render("tab switch field • enter create • esc back")
+
+	rows := []string{title, "", prompt, "", input, "", secretPrompt, "", secret}
+	rows = append(rows, "", m.styles.dimStyle.Render("Tags for the lobby:"),
+		m.styles.inputBoxStyle.Render(m.tagsInput.View()))
+	approval := "anyone with the code"
+	if m.approval {
+		approval = "host approves each joiner"
+	}
+	visibility := "private (join by code)"
+	if m.isPublic {
+		visibility = "public (listed in the lobby)"
+	}
+	rows = append(rows, "", m.styles.dimStyle.Render("Visibility (space to toggle):"),
+		m.renderPicker(visibility, m.createFocus == fieldPublic))
+	rows = append(rows, "", m.styles.dimStyle.Render("Who can walk in (space to toggle):"),
+		m.renderPicker(approval, m.createFocus == fieldApproval))
+	rows = append(rows, "", m.styles.dimStyle.Render("Mode (←/→ to choose):"), "",
+		m.renderPicker(room.Modes[m.modeIdx].String(), m.createFocus == fieldMode))
+	if templates := m.roomManager.Templates(); len(templates) > 0 {
+		name, desc := "none (bare shell)", ""
+		if m.templateIdx > 0 {
+			t := templates[m.templateIdx-1]
+			name, desc = t.Name, t.Description
+		}
+		rows = append(rows, "", m.styles.dimStyle.Render("Template (←/→ to choose):"), "",
+			m.renderPicker(name, m.createFocus == fieldTemplate))
+		if desc != "" {
+			rows = append(rows, m.styles.dimStyle.Render(desc))
+		}
+	}
+	rows = append(rows, help)
 
-	content := lipgloss.JoinVertical(lipgloss.Center,
-		title, "", prompt, "", input, help,
-	)
+	content := lipgloss.JoinVertical(lipgloss.Center, rows...)
 
 	view := lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
 
 	return view
 }
 
+// renderPicker draws a ‹ value › selector, highlighted when focused
+func (m *Model) renderPicker(value string, focused bool) string {
+	picker := "‹ " + value + " ›"
+	if focused {
+		return m.styles.accentStyle.Bold(true).Render(picker)
+	}
+	return m.styles.textStyle.Render(picker)
+}
+
 func (m *Model) viewJoin() string {
 	title := m.styles.titleStyle.Render("Join Room")
-	prompt := m.styles.textStyle.Render("Enter the room ID:")
+	prompt := m.styles.textStyle.Render("Enter the room code:")
 	input := m.styles.inputBoxStyle.Render(m.input.View())
-	help := m.styles.helpStyle.Render("enter join • esc back")
+	observe := "[ ] join as observer (read-only)"
+	if m.joinObserver {
+		observe = "[x] join as observer (read-only)"
+	}
+	observeLine := m.styles.dimStyle.Render(observe)
+	help := m.styles.helpStyle.Render("tab toggle observer • enter join • esc back")
 
 	// if room doesnt exist we show the toast
 	var errorLine string
@@ -55,7 +106,7 @@ func (m *Model) viewJoin() string {
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Center,
-		title, "", prompt, "", input, "", errorLine, help,
+		title, "", prompt, "", input, "", observeLine, "", errorLine, help,
 	)
 
 	view := lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
@@ -63,6 +114,24 @@ func (m *Model) viewJoin() string {
 	return view
 }
 
+func (m *Model) viewJoinAuth() string {
+	title := m.styles.titleStyle.Render("Room Locked")
+	prompt := m.styles.textStyle.Render("Enter the room password or an invite token:")
+	input := m.styles.inputBoxStyle.Render(m.secretInput.View())
+	help := m.styles.helpStyle.Render("enter join • esc back")
+
+	var errorLine string
+	if len(m.toasts) > 0 {
+		errorLine = m.styles.errorStyle.Render("▸ " + m.toasts[len(m.toasts)-1].text)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		title, "", prompt, "", input, "", errorLine, help,
+	)
+
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, content)
+}
+
 func (m *Model) viewRoomCreated() string {
 	title := m.styles.titleStyle.Render("Room Created!")
 
@@ -76,7 +145,7 @@ func (m *Model) viewRoomCreated() string {
 		Foreground(colorSuccess).
 		Render(m.roomID)
 
-	hint := m.styles.dimStyle.Render("(select and copy the code above)")
+	hint := m.styles.dimStyle.Render("(read it out or copy it - case doesn't matter)")
 	help := m.styles.helpStyle.Render("enter → enter room • esc back")
 
 	content := lipgloss.JoinVertical(lipgloss.Center,
@@ -115,7 +184,7 @@ func (m *Model) renderSidebar(w, h int) string {
 	var b strings.Builder
 
 	youLabel := m.styles.dimStyle.Render("you: ")
-	youName := m.styles.accentStyle.Bold(true).Render(m.username)
+	youName := m.nameStyle(m.name).Bold(true).Render(m.name)
 	b.WriteString(youLabel + youName + "\n\n")
 
 	roomLabel := m.styles.dimStyle.Render("room: ")
@@ -130,13 +199,45 @@ func (m *Model) renderSidebar(w, h int) string {
 		descText := m.styles.dimStyle.Render("      " + "\"" + desc + "\"")
 		b.WriteString(descText + "\n")
 	}
+	if m.currentRoom != nil && m.currentRoom.HasSecret() {
+		b.WriteString(m.styles.dimStyle.Render("      (password protected)") + "\n")
+	}
+	if !m.roomExpires.IsZero() {
+		left := max(0, time.Until(m.roomExpires).Round(time.Second))
+		b.WriteString(m.styles.errorStyle.Render(fmt.Sprintf("closing in %s (%s)", left, m.expiryReason)) + "\n")
+	}
+	if m.currentRoom != nil && m.currentRoom.Mode == room.ModeInterview {
+		b.WriteString(m.renderInterviewStatus(w))
+	}
+	if m.inviteToken != "" && time.Now().Before(m.inviteExpires) {
+		left := time.Until(m.inviteExpires).Round(time.Minute)
+		b.WriteString(m.styles.dimStyle.Render("invite: ") + m.styles.successStyle.Render(m.inviteToken) + "\n")
+		b.WriteString(m.styles.dimStyle.Render(fmt.Sprintf("      single use, %s left", left)) + "\n")
+	}
 	b.WriteString(m.styles.dimStyle.Render(strings.Repeat("─", w-2)) + "\n\n")
 
 	// Users
 	usersLabel := m.styles.dimStyle.Render(fmt.Sprintf("connected (%d):", len(m.users)))
 	b.WriteString(usersLabel + "\n")
 	for _, u := range m.users {
-		b.WriteString(m.styles.textStyle.Render("  • "+u) + "\n")
+		name := m.styles.people[u.Color%len(m.styles.people)].Render(u.Name)
+		b.WriteString(m.styles.textStyle.Render("  • ") + name + m.styles.textStyle.Render(u.Detail) + "\n")
+	}
+
+	// Keyboard holder
+	driver := m.styles.accentStyle.Render(truncate("free (ctrl+o to take)", w-14))
+	if m.currentRoom != nil {
+		if d := m.currentRoom.Driver(); d != nil {
+			name := d.Name
+			if d.ID == m.clientID {
+				name += " (you)"
+			}
+			driver = m.styles.people[d.Color%len(m.styles.people)].Render(truncate(name, w-14))
+		}
+	}
+	b.WriteString("\n" + m.styles.dimStyle.Render("keyboard: ") + driver + "\n")
+	if m.isMob() {
+		b.WriteString(m.renderMobQueue(w))
 	}
 
 	// Typing indicator
@@ -151,16 +252,67 @@ func (m *Model) renderSidebar(w, h int) string {
 	keysLabel := m.styles.dimStyle.Render("keys:")
 	b.WriteString(keysLabel + "\n")
 	b.WriteString(m.styles.textStyle.Render("  ctrl+g  AI prompt") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  ctrl+e  chat") + "\n")
 	b.WriteString(m.styles.textStyle.Render("  ctrl+a  toggle AI") + "\n")
-	b.WriteString(m.styles.textStyle.Render("  ctrl+j/k scroll AI") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  ctrl+b  next sidebar tab") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  ctrl+j/k scroll tab") + "\n")
 	b.WriteString(m.styles.textStyle.Render("  ctrl+r  run command") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  ctrl+o  take/give keyboard") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  alt+t/w new/close term") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  alt+r   rename term") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  alt+1-9 switch term") + "\n")
+	b.WriteString(m.styles.textStyle.Render("  alt+h   scroll history") + "\n")
+	if m.isClassroom() {
+		b.WriteString(m.styles.textStyle.Render("  alt+m   watch/my shell") + "\n")
+	}
+	if m.isMob() {
+		b.WriteString(m.styles.textStyle.Render("  alt+n   skip turn") + "\n")
+		b.WriteString(m.styles.textStyle.Render("  alt+↑/↓ move in queue") + "\n")
+	}
+	if m.isHost {
+		b.WriteString(m.styles.textStyle.Render("  ctrl+t  invite token") + "\n")
+		b.WriteString(m.styles.textStyle.Render("  alt+u   manage people") + "\n")
+		b.WriteString(m.styles.textStyle.Render("  alt+c   record terms") + "\n")
+		if m.isClassroom() {
+			b.WriteString(m.styles.textStyle.Render("  alt+v   roster/peek") + "\n")
+		}
+		if m.isMob() {
+			b.WriteString(m.styles.textStyle.Render("  alt+i   turn length") + "\n")
+		}
+		if m.currentRoom != nil && m.currentRoom.Mode == room.ModeInterview {
+			b.WriteString(m.styles.textStyle.Render("  alt+p   pin problem") + "\n")
+			b.WriteString(m.styles.textStyle.Render("  alt+s/e start/end timer") + "\n")
+		}
+	}
 	b.WriteString(m.styles.textStyle.Render("  ctrl+l  leave room") + "\n")
 
 	return m.styles.sidebarStyle.Width(w).Height(h).Render(b.String())
 }
 
 func (m *Model) renderTerminal(w, h int) string {
-	header := m.styles.titleStyle.Render("shared terminal")
+	if m.rosterOpen {
+		return m.renderRoster(w, h)
+	}
+	if m.peopleOpen {
+		return m.renderPeople(w, h)
+	}
+
+	header := m.renderTabStrip()
+	switch {
+	case m.privateTab != "":
+		header = m.styles.titleStyle.Render(m.privateTab)
+		if m.peekClientID != "" {
+			header += m.styles.dimStyle.Render("  (peeking, read-only)")
+		}
+	case m.currentRoom != nil && !m.canWrite():
+		header += m.styles.dimStyle.Render("  (read-only)")
+	}
+	if m.currentRoom != nil && m.currentRoom.Recording() && m.privateTab == "" {
+		header = m.styles.errorStyle.Bold(true).Render("● REC") + "  " + header
+	}
+	if m.scrolling {
+		header += m.styles.accentStyle.Render(m.scrollStatus())
+	}
 	content := m.termContent
 	if content == "" {
 		content = m.styles.dimStyle.Render("Starting terminal...")
@@ -171,6 +323,28 @@ func (m *Model) renderTerminal(w, h int) string {
 	)
 }
 
+// renderTabStrip draws "1 shell │ 2 server" with our tab highlighted
+func (m *Model) renderTabStrip() string {
+	if m.currentRoom == nil {
+		return m.styles.titleStyle.Render("shared terminal")
+	}
+	tabs := m.currentRoom.Tabs()
+	if len(tabs) == 0 {
+		return m.styles.titleStyle.Render("shared terminal")
+	}
+
+	parts := make([]string, len(tabs))
+	for i, t := range tabs {
+		label := fmt.Sprintf("%d %s", i+1, t.Name)
+		if t.ID == m.tabID {
+			parts[i] = m.styles.titleStyle.Render(label)
+		} else {
+			parts[i] = m.styles.dimStyle.Render(label)
+		}
+	}
+	return strings.Join(parts, m.styles.dimStyle.Render(" │ "))
+}
+
 func (m *Model) renderBottomBar() string {
 	// Right side: Mode status (always visible) similar to vim mode indicator
 	modeText := m.getModeStatus()
@@ -188,8 +362,13 @@ func (m *Model) renderBottomBar() string {
 		left = m.styles.accentStyle.Bold(true).Render(truncate(toastText, m.width-rightWidth-2))
 	} else if m.inputMode != ModeNormal {
 		left = m.cmdInput.View()
+	} else if knock := m.pendingKnock(); knock != "" {
+		left = m.styles.accentStyle.Bold(true).Render(truncate(knock, m.width-rightWidth-2))
+	} else if m.controlRequester != "" {
+		prompt := fmt.Sprintf("%s wants the keyboard • ctrl+y grant • ctrl+n deny", m.controlRequester)
+		left = m.styles.accentStyle.Bold(true).Render(truncate(prompt, m.width-rightWidth-2))
 	} else {
-		helpText := "ctrl+g AI • ctrl+a toggle AI • ctrl+r sandbox"
+		helpText := "ctrl+g AI • ctrl+e chat • ctrl+a toggle AI • ctrl+r sandbox"
 		left = m.styles.dimStyle.Render(truncate(helpText, m.width-rightWidth-2))
 	}
 
@@ -205,7 +384,22 @@ func (m *Model) getModeStatus() string {
 		return "-- AI --"
 	case ModeSandbox:
 		return "-- RUN --"
+	case ModeChat:
+		return "-- CHAT --"
+	case ModeRenameTab:
+		return "-- RENAME --"
+	case ModePinProblem:
+		return "-- PROBLEM --"
+	case ModeTimer:
+		return "-- TIMER --"
+	case ModeRotation:
+		return "-- ROTATION --"
+	case ModeModReason:
+		return "-- REASON --"
 	default:
+		if m.scrolling {
+			return "-- HISTORY --"
+		}
 		return "-- NORMAL --"
 	}
 }
@@ -229,10 +423,18 @@ func (m *Model) viewResizePrompt() string {
 func (m *Model) renderAISidebar(w, h int) string {
 	var b strings.Builder
 
-	header := m.styles.titleStyle.Render("AI Assistant")
-	b.WriteString(header + "\n")
+	b.WriteString(m.renderSidebarTabs() + "\n")
 	b.WriteString(m.styles.dimStyle.Render(strings.Repeat("─", w-4)) + "\n\n")
 
+	switch m.sidebarTab {
+	case TabChat:
+		m.renderChatTab(&b)
+		return m.styles.aiSidebarStyle.Width(w).Height(h).Render(b.String())
+	case TabProblem:
+		m.renderProblemTab(&b)
+		return m.styles.aiSidebarStyle.Width(w).Height(h).Render(b.String())
+	}
+
 	if m.aiLoading {
 		loadingText := fmt.Sprintf("%s Thinking...", m.aiSpinner.View())
 		b.WriteString(m.styles.accentStyle.Render(loadingText) + "\n\n")
@@ -253,6 +455,103 @@ func (m *Model) renderAISidebar(w, h int) string {
 	return m.styles.aiSidebarStyle.Width(w).Height(h).Render(b.String())
 }
 
+// renderSidebarTabs draws the "AI Assistant │ Chat" strip with the active tab highlighted
+func (m *Model) renderSidebarTabs() string {
+	tabs := m.sidebarTabs()
+	parts := make([]string, len(tabs))
+	for i, t := range tabs {
+		var label string
+		switch t {
+		case TabAI:
+			label = "AI Assistant"
+			if len(tabs) > 2 {
+				label = "AI" // make room for the problem tab
+			}
+		case TabChat:
+			label = "Chat"
+			if m.chatUnread > 0 {
+				label = fmt.Sprintf("Chat (%d)", m.chatUnread)
+			}
+		case TabProblem:
+			label = "Problem"
+		}
+
+		switch {
+		case t == m.sidebarTab:
+			parts[i] = m.styles.titleStyle.Render(label)
+		case t == TabChat && m.chatUnread > 0:
+			parts[i] = m.styles.accentStyle.Render(label)
+		default:
+			parts[i] = m.styles.dimStyle.Render(label)
+		}
+	}
+	return strings.Join(parts, m.styles.dimStyle.Render(" │ "))
+}
+
+// renderInterviewStatus shows the countdown, and the summary path to the host
+func (m *Model) renderInterviewStatus(w int) string {
+	var b strings.Builder
+	if !m.interviewDeadline.IsZero() {
+		left := max(0, time.Until(m.interviewDeadline).Round(time.Second))
+		style := m.styles.successStyle
+		if left < 5*time.Minute {
+			style = m.styles.errorStyle
+		}
+		b.WriteString(m.styles.dimStyle.Render("interview: ") + style.Bold(true).Render(left.String()+" left") + "\n")
+	} else {
+		b.WriteString(m.styles.dimStyle.Render("interview: not running") + "\n")
+	}
+	if m.isHost && m.summaryPath != "" {
+		b.WriteString(m.styles.dimStyle.Render("summary: ") + m.styles.textStyle.Render(truncate(m.summaryPath, w-13)) + "\n")
+	}
+	return b.String()
+}
+
+func (m *Model) renderChatTab(b *strings.Builder) {
+	if len(m.chatMessages) == 0 {
+		b.WriteString(m.styles.dimStyle.Render("No messages yet.\nPress ctrl+e to chat."))
+		return
+	}
+	b.WriteString(m.chatViewport.View())
+	scrollInfo := fmt.Sprintf(" %.0f%% ", m.chatViewport.ScrollPercent()*100)
+	b.WriteString("\n" + m.styles.dimStyle.Render(scrollInfo))
+}
+
+// buildChatContent formats the room chat, highlighting messages that mention us
+func (m *Model) buildChatContent(maxWidth int) string {
+	if maxWidth <= 0 {
+		maxWidth = 40
+	}
+
+	var b strings.Builder
+	for i, msg := range m.chatMessages {
+		name := msg.Username
+		if msg.ClientID == m.clientID {
+			name = "you"
+		}
+		prefix := m.nameStyle(msg.Username).Render(name + ": ")
+		textStyle := m.styles.textStyle
+		if msg.ClientID != m.clientID && msg.MentionsUser(m.name) {
+			textStyle = m.styles.accentStyle.Bold(true)
+		}
+
+		wrapped := wordwrap.String(msg.Text, maxWidth-4)
+		for j, line := range strings.Split(wrapped, "\n") {
+			if j == 0 {
+				b.WriteString(prefix + textStyle.Render(line))
+			} else {
+				b.WriteString("    " + textStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+
+		if i < len(m.chatMessages)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
 // formatting content for viewport with proper line tracking
 func (m *Model) buildAIContent(maxWidth int) (string, int) {
 	if maxWidth <= 0 {
@@ -275,7 +574,7 @@ func (m *Model) buildAIContent(maxWidth int) (string, int) {
 			if username == "" {
 				username = "you"
 			}
-			prefix = m.styles.accentStyle.Render(username + ": ")
+			prefix = m.nameStyle(msg.UserID).Render(username + ": ")
 			isUser = true
 			// Track the line offset where this user prompt starts
 			lastPromptOffset = currentLine
@@ -4,7 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/jaypopat/duet/internal/room"
 	"github.com/jaypopat/duet/internal/server"
 )
 
@@ -12,12 +14,40 @@ func main() {
 	addr := flag.String("addr", ":2222", "SSH server address")
 	hostKeyPath := flag.String("hostkey", ".ssh/id_ed25519", "Path to SSH host key")
 	workerURL := flag.String("worker", "", "Duet CF Worker base URL (e.g. https://duet-cf-worker.<subdomain>.workers.dev)")
+	dataDir := flag.String("data", "", "Directory to persist rooms in across restarts (empty keeps them in memory)")
+	grace := flag.Duration("grace", 2*time.Minute, "How long an empty room keeps its terminal alive for reconnecting users")
+	idleTimeout := flag.Duration("idle-timeout", 30*time.Minute, "Close rooms with no activity for this long (0 disables)")
+	maxAge := flag.Duration("max-age", 12*time.Hour, "Close rooms this long after creation (0 disables)")
+	templatesPath := flag.String("templates", "", "JSON file of room templates to offer on the create screen")
+	summaryDir := flag.String("summaries", "summaries", "Directory interview summaries are written to")
+	recordingDir := flag.String("recordings", "recordings", "Directory terminal recordings are written to")
 	flag.Parse()
 
 	fmt.Println("Duet - SSH Pair Programming")
 	fmt.Printf("Starting server on %s\n", *addr)
 
-	srv := server.New(*addr, *hostKeyPath, *workerURL)
+	var templates []room.Template
+	if *templatesPath != "" {
+		var err error
+		templates, err = room.LoadTemplates(*templatesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	srv, err := server.New(*addr, *hostKeyPath, *workerURL, *dataDir, room.Config{
+		ReconnectGrace: *grace,
+		IdleTimeout:    *idleTimeout,
+		MaxAge:         *maxAge,
+		Templates:      templates,
+		SummaryDir:     *summaryDir,
+		RecordingDir:   *recordingDir,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
 	if err := srv.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 		os.Exit(1)